@@ -1,80 +1,490 @@
 package epp
 
 import (
-	"crypto/tls"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
+	"sync/atomic"
 	"time"
+
+	ierr "github.com/ParadoxTR/epp-at-go/internal/errors"
 )
 
+// ErrSessionPoisoned is returned once an exchange was interrupted mid-frame
+// (cancellation, deadline or I/O failure). The stream may still hold a
+// partially written request or unread response, so the session must be
+// re-established with Connect before it can be used again.
+var ErrSessionPoisoned = errors.New("EPP session poisoned by an interrupted exchange; reconnect required")
+
+// sessionLock is a mutex whose waiters can give up when their context ends,
+// so a caller with an expired deadline does not queue behind a slow command.
+type sessionLock chan struct{}
+
+func newSessionLock() sessionLock {
+	return make(sessionLock, 1)
+}
+
+func (l sessionLock) Lock() {
+	l <- struct{}{}
+}
+
+// LockContext acquires the lock or returns the context's error once ctx is
+// done.
+func (l sessionLock) LockContext(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l sessionLock) TryLock() bool {
+	select {
+	case l <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l sessionLock) Unlock() {
+	<-l
+}
+
+// Client is an EPP session with a nic.at registry server.
+//
+// A Client is safe for concurrent use by multiple goroutines. Commands are
+// serialized: each request/response exchange, including a transparent
+// reconnect and the keepalive hello, runs to completion before the next one
+// starts, so responses can never be attributed to the wrong caller. Callers
+// waiting for their turn are not bounded by the command deadline, but give
+// up when their context ends; use a Pool to run commands in parallel.
+// Connect, Login, ChangePassword, Logout and Close take the same lock, so
+// credentials and connection state are never observed mid-update.
 type Client struct {
-	conn     net.Conn
-	hostname string
-	port     int
-	username string
-	password string
-	timeout  time.Duration
+	mu                 sessionLock // Serializes frame exchanges and guards session state
+	conn               net.Conn
+	hostname           string
+	port               int
+	credentials        CredentialProvider
+	activePassword     string // Password changed on this session but not yet persisted
+	timeout            time.Duration
+	commandTimeout     time.Duration
+	slowCommandTimeout time.Duration
+	commandTimeouts    TimeoutTable
+	tls                TLSOptions
+	autoReconnect      bool
+	poisoned           bool
+	loggedIn           bool
+	closing            bool         // A logout is in progress
+	state              atomic.Int32 // SessionState published by updateState
+	lastActivity       time.Time
+	greeting           *Greeting
+	clockSkew          time.Duration
+	objectURIs         []string
+	extensionURIs      []string
+	requiredURIs       []string
+	extensions         []string // Extension URIs negotiated at login
+	keepalive          keepalive
+	retry              RetryPolicy
+	limiter            *RateLimiter
+	dialer             Dialer
+	transport          Transport
+	limits             frameLimits
+	interceptors       []Interceptor
+	wire               []Interceptor // Built-in interceptors, innermost so they see the frames as sent
+	logger             *slog.Logger
+	trIDGenerator      TrIDGenerator
+	trIDPrefix         string
+	metrics            Metrics
+	tracer             Tracer
 }
 
 type Config struct {
-	Hostname string        // EPP server hostname
-	Port     int           // EPP server port (typically 700)
-	Username string        // EPP account username
-	Password string        // EPP account password
-	Timeout  time.Duration // Connection timeout duration
+	Hostname           string             // EPP server hostname
+	Port               int                // EPP server port (typically 700)
+	Username           string             // EPP account username
+	Password           string             // EPP account password
+	Credentials        CredentialProvider // Consulted at every login instead of Username and Password, e.g. FileCredentials
+	Timeout            time.Duration      // Connection timeout duration
+	CommandTimeout     time.Duration      // Per-exchange deadline for hello, login, check, info and poll
+	SlowCommandTimeout time.Duration      // Per-exchange deadline for create, update, delete, transfer and withdraw
+	CommandTimeouts    TimeoutTable       // Per-exchange deadlines by command type, overriding the two above
+	TLS                TLSOptions         // Client certificate, CA bundle, minimum version and key pinning
+	AutoReconnect      bool               // Re-dial and re-login after session loss, retrying idempotent commands
+	KeepaliveInterval  time.Duration      // Send hello after this much idle time once logged in; zero disables
+	OnKeepaliveError   func(error)        // Called when a keepalive hello fails
+	ObjectURIs         []string           // Object services to announce at login, defaults to DefaultObjectURIs
+	ExtensionURIs      []string           // Extension services to announce at login, defaults to DefaultExtensionURIs
+	RequiredServices   []string           // Login fails when the greeting does not offer one of these URIs
+	Retry              RetryPolicy        // Backoff and attempts for transient failures; zero value disables retries
+	RateLimiter        *RateLimiter       // Throttles commands per type; share one limiter to apply an account-wide quota
+	Dialer             Dialer             // Opens the TCP connection under TLS, e.g. ProxyDialer; defaults to net.Dialer
+	Transport          Transport          // Replaces dialing and TLS, e.g. ConnTransport or PipeTransport
+	MaxFrameSize       int                // Largest accepted response frame in bytes, defaults to DefaultMaxFrameSize
+	MaxXMLDepth        int                // Deepest accepted element nesting, defaults to DefaultMaxXMLDepth
+	MaxXMLTokens       int                // Most XML tokens accepted per response, defaults to DefaultMaxXMLTokens
+	Interceptors       []Interceptor      // Wrap every frame exchange, the first one outermost
+	Logger             *slog.Logger       // Receives command records and, at debug level, redacted frames; nil disables logging
+	Redaction          RedactionPolicy    // Personal data masked in logged and journaled frames
+	Journal            JournalSink        // Records every frame sent and received, e.g. a Journal file; nil disables
+	TrIDGenerator      TrIDGenerator      // Produces client transaction IDs, defaults to ULIDTrIDGenerator
+	TrIDPrefix         string             // Registrar prefix prepended to generated client transaction IDs
+	Metrics            Metrics            // Receives latency, size, result code, session and poll queue observations; nil disables
+	Tracer             Tracer             // Opens a span per command, e.g. an OpenTelemetry adapter; nil disables
 }
 
 func NewClient(config Config) *Client {
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
 	}
+	if config.CommandTimeout == 0 {
+		config.CommandTimeout = 30 * time.Second
+	}
+	if config.SlowCommandTimeout == 0 {
+		config.SlowCommandTimeout = 120 * time.Second
+	}
+	if config.MaxFrameSize == 0 {
+		config.MaxFrameSize = DefaultMaxFrameSize
+	}
+	if config.MaxXMLDepth == 0 {
+		config.MaxXMLDepth = DefaultMaxXMLDepth
+	}
+	if config.MaxXMLTokens == 0 {
+		config.MaxXMLTokens = DefaultMaxXMLTokens
+	}
+	if config.ObjectURIs == nil {
+		config.ObjectURIs = DefaultObjectURIs
+	}
+	if config.ExtensionURIs == nil {
+		config.ExtensionURIs = DefaultExtensionURIs
+	}
 
-	return &Client{
-		hostname: config.Hostname,
-		port:     config.Port,
-		username: config.Username,
-		password: config.Password,
-		timeout:  config.Timeout,
+	if config.TrIDGenerator == nil {
+		config.TrIDGenerator = ULIDTrIDGenerator()
+	}
+	if config.Credentials == nil {
+		config.Credentials = StaticCredentials{Username: config.Username, Password: config.Password}
+	}
+
+	client := &Client{
+		mu:                 newSessionLock(),
+		hostname:           config.Hostname,
+		port:               config.Port,
+		credentials:        config.Credentials,
+		timeout:            config.Timeout,
+		commandTimeout:     config.CommandTimeout,
+		slowCommandTimeout: config.SlowCommandTimeout,
+		commandTimeouts:    config.CommandTimeouts.clone(),
+		tls:                config.TLS,
+		autoReconnect:      config.AutoReconnect,
+		objectURIs:         config.ObjectURIs,
+		extensionURIs:      config.ExtensionURIs,
+		requiredURIs:       config.RequiredServices,
+		keepalive: keepalive{
+			interval: config.KeepaliveInterval,
+			onError:  config.OnKeepaliveError,
+		},
+		retry:     config.Retry.withDefaults(),
+		limiter:   config.RateLimiter,
+		dialer:    config.Dialer,
+		transport: config.Transport,
+		limits: frameLimits{
+			maxFrameSize: config.MaxFrameSize,
+			maxXMLDepth:  config.MaxXMLDepth,
+			maxXMLTokens: config.MaxXMLTokens,
+		},
+		interceptors:  append([]Interceptor(nil), config.Interceptors...),
+		logger:        config.Logger,
+		trIDGenerator: config.TrIDGenerator,
+		trIDPrefix:    config.TrIDPrefix,
+		metrics:       config.Metrics,
+		tracer:        config.Tracer,
 	}
+
+	redactor := config.Redaction.redactor()
+	if config.Logger != nil {
+		client.wire = append(client.wire, loggingInterceptor(config.Logger, redactor))
+	}
+	if config.Journal != nil {
+		client.wire = append(client.wire, journalInterceptor(config.Journal, redactor, config.Logger))
+	}
+	if config.Metrics != nil {
+		client.wire = append(client.wire, metricsInterceptor(config.Metrics))
+	}
+
+	return client
 }
 
 func (c *Client) Connect() error {
-	address := fmt.Sprintf("%s:%d", c.hostname, c.port)
+	return c.ConnectContext(context.Background())
+}
+
+// ConnectContext opens a new session. It fails with a *StateError while a
+// usable connection exists; a poisoned one is replaced, and the new session
+// has to log in again.
+func (c *Client) ConnectContext(ctx context.Context) error {
+	if err := c.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
 
-	tlsConfig := &tls.Config{
-		ServerName: c.hostname,
+	if c.conn != nil && !c.poisoned {
+		return &StateError{Command: "connect", State: c.State()}
 	}
+	c.loggedIn = false
+	c.dropConnection()
 
-	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: c.timeout}, "tcp", address, tlsConfig)
+	return c.connect(ctx)
+}
+
+// connect dials the server and reads its greeting, traced as one span.
+func (c *Client) connect(ctx context.Context) error {
+	ctx, finish := c.startSpan(ctx, command{name: "connect", typ: CommandHello}, nil)
+	greeting, err := c.openSession(ctx)
+	finish(greeting, err)
+	return err
+}
+
+func (c *Client) openSession(ctx context.Context) (*reply, error) {
+	transport, err := c.transportFor()
 	if err != nil {
-		return fmt.Errorf("failed to establish TLS connection to EPP server: %w", err)
+		return nil, err
+	}
+
+	conn, err := transport.Dial(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to establish connection to EPP server: %w", err)
 	}
 
 	c.conn = conn
+	c.poisoned = false
+	if c.metrics != nil {
+		c.metrics.SessionOpened()
+	}
 
-	_, err = c.readResponse()
+	greeting, err := c.exchange(ctx, command{name: "greeting", typ: CommandHello}, nil)
 	if err != nil {
-		c.conn.Close()
-		return fmt.Errorf("failed to read server greeting: %w", err)
+		c.dropConnection()
+		return nil, fmt.Errorf("failed to read server greeting: %w", err)
 	}
 
-	return nil
+	if _, err := c.recordGreeting(greeting.frame, time.Now()); err != nil {
+		c.dropConnection()
+		return nil, err
+	}
+	c.updateState()
+
+	return greeting, nil
 }
 
+// Close ends the session: an authenticated session is logged out first,
+// then the connection is closed. Errors of both steps are returned.
 func (c *Client) Close() error {
+	c.stopKeepalive()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var logoutErr error
+	if c.loggedIn && c.conn != nil && !c.poisoned {
+		logoutErr = c.logout(context.Background())
+	}
+
+	c.loggedIn = false
+	return errors.Join(logoutErr, c.dropConnection())
+}
+
+// dropConnection closes the transport but keeps the login state so that a
+// resilient client can restore the session.
+func (c *Client) dropConnection() error {
+	c.poisoned = false
+	defer c.updateState()
 	if c.conn != nil {
-		return c.conn.Close()
+		err := c.conn.Close()
+		c.conn = nil
+		if c.metrics != nil {
+			c.metrics.SessionClosed()
+		}
+		return err
+	}
+	return nil
+}
+
+// timeoutFor returns the per-exchange deadline of a command: the override
+// in ctx, else the configured timeout for its type, else the default of its
+// class.
+func (c *Client) timeoutFor(ctx context.Context, typ CommandType) time.Duration {
+	if timeout, ok := ctx.Value(commandTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	if timeout, ok := c.commandTimeouts[typ]; ok {
+		return timeout
+	}
+	if typ.slow() {
+		return c.slowCommandTimeout
+	}
+	return c.commandTimeout
+}
+
+// watchConn applies the earlier of the context deadline and timeout, if
+// positive, to conn and aborts blocked reads and writes when the context is
+// cancelled. It reports whether the context deadline was the one applied.
+// The returned function must be called once the exchange or handshake has
+// finished; it clears the deadline again.
+func watchConn(ctx context.Context, conn net.Conn, timeout time.Duration) (stop func(), ctxDeadline bool) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+		ctxDeadline = true
+	}
+	_ = conn.SetDeadline(deadline)
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		select {
+		case <-ctx.Done():
+			// A deadline in the past unblocks any pending Read or Write.
+			_ = conn.SetDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	stop = func() {
+		close(done)
+		<-finished
+		_ = conn.SetDeadline(time.Time{})
+	}
+	return stop, ctxDeadline
+}
+
+// contextError returns the context's error wrapping err, the I/O error it
+// caused, or nil when the context did not end the exchange. The connection
+// can time out on a deadline taken from the context before ctx.Err is set,
+// so such a timeout counts as context.DeadlineExceeded as well.
+func contextError(ctx context.Context, ctxDeadline bool, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && (ctxDeadline || !time.Now().Before(deadline)) {
+		return fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
 	}
 	return nil
 }
 
-func (c *Client) sendRequest(request []byte) ([]byte, error) {
-	if c.conn == nil {
-		return nil, fmt.Errorf("client not connected to EPP server")
+// exchangeError prefers the context's error over the I/O error it caused so
+// callers can test for context.Canceled and context.DeadlineExceeded, and
+// reports an expired command deadline as a *TimeoutError.
+func exchangeError(ctx context.Context, cmd command, timeout time.Duration, ctxDeadline, awaitingResponse bool, err error) error {
+	if ctxErr := contextError(ctx, ctxDeadline, err); ctxErr != nil {
+		return ctxErr
+	}
+	var netErr net.Error
+	if timeout > 0 && errors.As(err, &netErr) && netErr.Timeout() {
+		return &TimeoutError{
+			Command:          cmd.name,
+			Type:             cmd.typ,
+			Duration:         timeout,
+			AwaitingResponse: awaitingResponse,
+			Err:              err,
+		}
+	}
+	return err
+}
+
+// roundTrip performs one command exchange, restoring a lost session first
+// when the client is resilient. The caller must hold c.mu.
+func (c *Client) roundTrip(ctx context.Context, cmd command, request []byte) (*reply, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := c.checkState(cmd); err != nil {
+		return nil, err
+	}
+	if c.canReconnect() && (c.conn == nil || c.poisoned) {
+		if err := c.reconnect(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if c.poisoned {
+		return nil, ErrSessionPoisoned
+	}
+	// Checked after reconnecting, a restored session may offer other extensions.
+	if err := c.checkExtensions(cmd); err != nil {
+		return nil, err
+	}
+
+	response, err := c.exchange(ctx, cmd, request)
+	if c.shouldRetryAfterSessionLoss(ctx, cmd, response, err) {
+		if reconnectErr := c.reconnect(ctx); reconnectErr != nil {
+			return nil, fmt.Errorf("%w (session lost: %v)", reconnectErr, sessionLossCause(response, err))
+		}
+		response, err = c.exchange(ctx, cmd, request)
+	}
+
+	return response, err
+}
+
+// transmit writes request, if any, and reads the next frame under the
+// command deadline. Any failure poisons the session because the stream
+// position is unknown afterwards.
+func (c *Client) transmit(ctx context.Context, cmd command, request []byte) (*reply, error) {
+	timeout := c.timeoutFor(ctx, cmd.typ)
+	stop, ctxDeadline := watchConn(ctx, c.conn, timeout)
+	defer stop()
+
+	c.lastActivity = time.Now()
+
+	if request != nil {
+		if err := c.writeRequest(request); err != nil {
+			c.poisoned = true
+			c.updateState()
+			return nil, exchangeError(ctx, cmd, timeout, ctxDeadline, false, err)
+		}
 	}
 
+	response, err := c.readResponse()
+	if err == nil {
+		err = c.limits.checkDocument(response)
+	}
+	var protocolErr *ProtocolError
+	if errors.As(err, &protocolErr) {
+		// Unread or untrusted bytes may follow, the stream cannot be reused.
+		c.dropConnection()
+		protocolErr.Command = cmd.name
+		return nil, protocolErr
+	}
+	if err != nil {
+		c.poisoned = true
+		c.updateState()
+		return nil, exchangeError(ctx, cmd, timeout, ctxDeadline, true, err)
+	}
+
+	reply := newReply(cmd, response)
+
+	// The server closes the connection right after a 2500-2502 response.
+	if ClassifyResultCode(reply.code()) == ResultSessionClosing {
+		c.dropConnection()
+	}
+
+	return reply, nil
+}
+
+func (c *Client) writeRequest(request []byte) error {
 	length := uint32(len(request) + 4)
 	header := []byte{
 		byte(length >> 24),
@@ -84,27 +494,21 @@ func (c *Client) sendRequest(request []byte) ([]byte, error) {
 	}
 
 	if _, err := c.conn.Write(append(header, request...)); err != nil {
-		return nil, fmt.Errorf("failed to send EPP request: %w", err)
-	}
-
-	response, err := c.readResponse()
-	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to send EPP request: %w", err)
 	}
 
-	return response, nil
+	return nil
 }
 
 func (c *Client) readResponse() ([]byte, error) {
-
 	header := make([]byte, 4)
 	if _, err := io.ReadFull(c.conn, header); err != nil {
 		return nil, fmt.Errorf("failed to read EPP response header: %w", err)
 	}
 
 	length := uint32(header[0])<<24 | uint32(header[1])<<16 | uint32(header[2])<<8 | uint32(header[3])
-	if length < 4 {
-		return nil, fmt.Errorf("invalid EPP response length: %d", length)
+	if err := c.limits.checkLength(length); err != nil {
+		return nil, err
 	}
 
 	body := make([]byte, length-4)
@@ -116,32 +520,48 @@ func (c *Client) readResponse() ([]byte, error) {
 }
 
 func (c *Client) Login() error {
+	return c.LoginContext(context.Background())
+}
+
+func (c *Client) LoginContext(ctx context.Context) error {
+	if err := c.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	return c.login(ctx)
+}
+
+func (c *Client) login(ctx context.Context) error {
+	services, err := c.loginServices()
+	if err != nil {
+		return err
+	}
+
+	credentials, err := c.currentCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return err
+	}
+
 	loginReq := LoginRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
 		Command: LoginCommand{
 			Login: Login{
-				ClID: c.username,
-				Pw:   c.password,
+				ClID: credentials.Username,
+				Pw:   credentials.Password,
 				Options: LoginOptions{
 					Version: "1.0",
 					Lang:    "en",
 				},
-				Svcs: LoginServices{
-					ObjURI: []string{
-						"urn:ietf:params:xml:ns:domain-1.0",
-						"urn:ietf:params:xml:ns:contact-1.0",
-					},
-					SvcExtension: &LoginServiceExtension{
-						ExtURI: []string{
-							"http://www.nic.at/xsd/at-ext-epp-1.0",
-							"http://www.nic.at/xsd/at-ext-domain-1.0",
-							"http://www.nic.at/xsd/at-ext-contact-1.0",
-						},
-					},
-				},
+				Svcs: services,
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -150,30 +570,78 @@ func (c *Client) Login() error {
 		return fmt.Errorf("failed to marshal login request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	cmd := command{name: "login", typ: CommandLogin}
+	ctx, finish := c.startSpan(ctx, cmd, requestXML)
+	reply, err := c.roundTrip(ctx, cmd, requestXML)
+	finish(reply, err)
 	if err != nil {
 		return fmt.Errorf("failed to send login request: %w", err)
 	}
 
 	var response Response
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return fmt.Errorf("failed to unmarshal login response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return fmt.Errorf("EPP login failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return newEPPError("login", reply)
 	}
 
+	c.establishSession(services)
+
 	return nil
 }
 
 func (c *Client) Logout() error {
+	return c.LogoutContext(context.Background())
+}
+
+// LogoutContext ends an authenticated session with an orderly shutdown: it
+// sends logout, expects 1500 and closes the connection, which the server
+// closes as well. A session that was already lost is only marked as logged
+// out.
+func (c *Client) LogoutContext(ctx context.Context) error {
+	c.stopKeepalive()
+
+	if err := c.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	if !c.loggedIn {
+		return &StateError{Command: "logout", State: c.State()}
+	}
+	if c.conn == nil || c.poisoned {
+		c.loggedIn = false
+		c.dropConnection()
+		return nil
+	}
+
+	return c.logout(ctx)
+}
+
+// logout sends logout on a live authenticated session and closes the
+// connection afterwards, whatever the outcome. The caller must hold c.mu.
+func (c *Client) logout(ctx context.Context) error {
+	c.closing = true
+	c.updateState()
+	defer func() {
+		c.closing = false
+		c.loggedIn = false
+		c.dropConnection()
+	}()
+
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return err
+	}
+
 	logoutReq := LogoutRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
 		Command: LogoutCommand{
 			Logout: struct{}{},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -182,10 +650,17 @@ func (c *Client) Logout() error {
 		return fmt.Errorf("failed to marshal logout request: %w", err)
 	}
 
-	_, err = c.sendRequest(requestXML)
+	cmd := command{name: "logout", typ: CommandLogout}
+	ctx, finish := c.startSpan(ctx, cmd, requestXML)
+	reply, err := c.roundTrip(ctx, cmd, requestXML)
+	finish(reply, err)
 	if err != nil {
 		return fmt.Errorf("failed to send logout request: %w", err)
 	}
 
+	if reply.code() != ierr.CodeSuccessEndingSession {
+		return newEPPError("logout", reply)
+	}
+
 	return nil
 }
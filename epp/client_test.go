@@ -0,0 +1,261 @@
+package epp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testGreeting = `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><greeting><svID>test</svID><svDate>2026-01-01T00:00:00Z</svDate>` +
+	`<svcMenu><version>1.0</version><lang>en</lang>` +
+	`<objURI>urn:ietf:params:xml:ns:domain-1.0</objURI><objURI>urn:ietf:params:xml:ns:contact-1.0</objURI>` +
+	`<svcExtension><extURI>http://www.nic.at/xsd/at-ext-domain-1.0</extURI></svcExtension>` +
+	`</svcMenu></greeting></epp>`
+
+var clTRIDPattern = regexp.MustCompile(`<clTRID>([^<]*)</clTRID>`)
+
+// testServer answers every request with the frame returned by respond,
+// after sending the greeting. It serves each connection of a PipeTransport.
+func testServer(t *testing.T, respond func(request string) string) Transport {
+	t.Helper()
+	return PipeTransport(func(conn net.Conn) {
+		defer conn.Close()
+		if err := writeTestFrame(conn, testGreeting); err != nil {
+			return
+		}
+		for {
+			request, err := readTestFrame(conn)
+			if err != nil {
+				return
+			}
+			if err := writeTestFrame(conn, respond(request)); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// testResponse builds a response frame echoing the clTRID of request.
+func testResponse(request, code, body string) string {
+	var clTRID string
+	if match := clTRIDPattern.FindStringSubmatch(request); match != nil {
+		clTRID = match[1]
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>`+
+		`<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><response><result code="%s"><msg>test</msg></result>%s`+
+		`<trID><clTRID>%s</clTRID><svTRID>sv-%s</svTRID></trID></response></epp>`, code, body, clTRID, clTRID)
+}
+
+func writeTestFrame(w io.Writer, frame string) error {
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, uint32(len(frame)+4))
+	_, err := w.Write(append(header, frame...))
+	return err
+}
+
+func readTestFrame(r io.Reader) (string, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return "", err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header)-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func TestClientConcurrentCommandsReceiveOwnResponses(t *testing.T) {
+	domainPattern := regexp.MustCompile(`<domain:name>([^<]*)</domain:name>`)
+	transport := testServer(t, func(request string) string {
+		var body string
+		if match := domainPattern.FindStringSubmatch(request); match != nil {
+			body = `<resData><domain:chkData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">` +
+				`<domain:cd><domain:name avail="1">` + match[1] + `</domain:name></domain:cd></domain:chkData></resData>`
+		}
+		return testResponse(request, "1000", body)
+	})
+
+	client := NewClient(Config{Username: "user", Password: "secret", Transport: transport})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	defer client.Close()
+
+	const callers = 32
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			clTRID := fmt.Sprintf("caller-%02d", i)
+			domain := fmt.Sprintf("domain-%02d.at", i)
+			response, err := client.CheckDomainContext(WithClTRID(context.Background(), clTRID), []string{domain})
+			if err != nil {
+				errs <- fmt.Errorf("%s: %w", clTRID, err)
+				return
+			}
+			if response.TrID.ClTRID != clTRID {
+				errs <- fmt.Errorf("%s received the response for %s", clTRID, response.TrID.ClTRID)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestClientChecksStateBeforeExtensions(t *testing.T) {
+	transport := testServer(t, func(request string) string {
+		return testResponse(request, "1000", "")
+	})
+
+	client := NewClient(Config{Username: "user", Password: "secret", Transport: transport})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	_, err := client.DeleteDomain("example.at")
+	var stateErr *StateError
+	if !errors.As(err, &stateErr) {
+		t.Fatalf("DeleteDomain before Login returned %v, want a *StateError", err)
+	}
+	if stateErr.State != StateGreeted {
+		t.Errorf("StateError.State = %s, want %s", stateErr.State, StateGreeted)
+	}
+}
+
+func TestClientGreetingReturnsCopy(t *testing.T) {
+	transport := testServer(t, func(request string) string {
+		return testResponse(request, "1000", "")
+	})
+
+	client := NewClient(Config{Username: "user", Password: "secret", Transport: transport})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	greeting := client.Greeting()
+	greeting.SvcMenu.ObjURI[0] = "changed"
+	greeting.SvcMenu.SvcExt[0] = "changed"
+
+	again := client.Greeting()
+	if again.SvcMenu.ObjURI[0] != NamespaceDomain || again.SvcMenu.SvcExt[0] != NamespaceAtExtDomain {
+		t.Errorf("modifying a returned greeting changed the client's: %v, %v", again.SvcMenu.ObjURI, again.SvcMenu.SvcExt)
+	}
+}
+
+func TestClientShortContextDeadlineAgainstSlowServer(t *testing.T) {
+	transport := testServer(t, func(request string) string {
+		if strings.Contains(request, "<domain:check") {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return testResponse(request, "1000", "")
+	})
+
+	// The connection deadline races with the context's timer; repeat to
+	// cover both orders.
+	for i := 0; i < 10; i++ {
+		client := NewClient(Config{Username: "user", Password: "secret", Transport: transport})
+		if err := client.Connect(); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+		if err := client.Login(); err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, err := client.CheckDomainContext(ctx, []string{"example.at"})
+		cancel()
+		client.Close()
+
+		var timeoutErr *TimeoutError
+		if !errors.Is(err, context.DeadlineExceeded) || errors.As(err, &timeoutErr) {
+			t.Fatalf("CheckDomain with an expired context returned %v, want context.DeadlineExceeded", err)
+		}
+	}
+}
+
+func TestExchangeErrorAttributesTimeoutToDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	cmd := command{name: "domain:check", typ: CommandCheck}
+
+	// The connection may time out on the context's deadline before the
+	// context itself reports it.
+	err := exchangeError(ctx, cmd, 30*time.Second, true, true, os.ErrDeadlineExceeded)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("timeout on the context deadline returned %v, want context.DeadlineExceeded", err)
+	}
+
+	err = exchangeError(ctx, cmd, 30*time.Second, false, true, os.ErrDeadlineExceeded)
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("timeout on the command deadline returned %v, want a *TimeoutError", err)
+	}
+}
+
+func TestClientWaitingForSessionHonorsContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	transport := testServer(t, func(request string) string {
+		if strings.Contains(request, "slow.at") {
+			close(started)
+			<-release
+		}
+		return testResponse(request, "1000", "")
+	})
+
+	client := NewClient(Config{Username: "user", Password: "secret", Transport: transport})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	defer client.Close()
+
+	slowDone := make(chan error, 1)
+	go func() {
+		_, err := client.CheckDomain([]string{"slow.at"})
+		slowDone <- err
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	_, err := client.CheckDomainContext(ctx, []string{"example.at"})
+	waited := time.Since(begin)
+	close(release)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CheckDomain waiting behind a slow command returned %v, want context.DeadlineExceeded", err)
+	}
+	if waited > time.Second {
+		t.Errorf("CheckDomain waited %s for the session, beyond its context", waited)
+	}
+	if err := <-slowDone; err != nil {
+		t.Errorf("slow CheckDomain: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package epp
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CommandType groups EPP commands by the kind of work the registry performs.
+type CommandType string
+
+const (
+	CommandHello    CommandType = "hello"
+	CommandLogin    CommandType = "login"
+	CommandLogout   CommandType = "logout"
+	CommandCheck    CommandType = "check"
+	CommandInfo     CommandType = "info"
+	CommandCreate   CommandType = "create"
+	CommandUpdate   CommandType = "update"
+	CommandDelete   CommandType = "delete"
+	CommandTransfer CommandType = "transfer"
+	CommandPoll     CommandType = "poll"
+	CommandWithdraw CommandType = "withdraw"
+)
+
+// slow reports whether the registry does substantial work for the command
+// (nameserver checks, transfer processing) before it answers.
+func (t CommandType) slow() bool {
+	switch t {
+	case CommandCreate, CommandUpdate, CommandDelete, CommandTransfer, CommandWithdraw:
+		return true
+	default:
+		return false
+	}
+}
+
+// command describes a single frame exchange for the transport layer.
+type command struct {
+	name       string // EPP element, e.g. "domain:check"
+	typ        CommandType
+	object     string   // Domain name, contact ID or poll message ID, if any
+	idempotent bool     // Safe to replay when the response was lost
+	extensions []string // Extension URIs the request uses
+}
+
+// TimeoutTable maps command types to their per-exchange deadline. A zero
+// duration disables the deadline for that type.
+type TimeoutTable map[CommandType]time.Duration
+
+func (t TimeoutTable) clone() TimeoutTable {
+	if t == nil {
+		return nil
+	}
+	clone := make(TimeoutTable, len(t))
+	for typ, timeout := range t {
+		clone[typ] = timeout
+	}
+	return clone
+}
+
+type commandTimeoutKey struct{}
+
+// WithCommandTimeout returns a context that applies d as the per-exchange
+// deadline of the command sent with it, overriding the configured timeout
+// for its type. Unlike a context deadline, an expired command timeout is
+// reported as a *TimeoutError.
+func WithCommandTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, commandTimeoutKey{}, d)
+}
+
+// TimeoutError is returned when a frame exchange exceeds its per-command
+// deadline. The session is poisoned afterwards because the response may
+// still arrive on the stream.
+type TimeoutError struct {
+	Command  string
+	Type     CommandType
+	Duration time.Duration
+	// AwaitingResponse is set when the request had been sent and the
+	// registry did not answer in time; otherwise sending the request
+	// stalled, which points at the network rather than the registry.
+	AwaitingResponse bool
+	Err              error
+}
+
+func (e *TimeoutError) Error() string {
+	phase := "sending the request"
+	if e.AwaitingResponse {
+		phase = "waiting for the response"
+	}
+	return fmt.Sprintf("EPP %s timed out after %s %s: %v", e.Command, e.Duration, phase, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
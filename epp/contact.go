@@ -1,10 +1,9 @@
 package epp
 
 import (
+	"context"
 	"encoding/xml"
-	"errors"
 	"fmt"
-	"log"
 	"strings"
 	"unicode/utf8"
 
@@ -52,11 +51,20 @@ type AtContactExtension struct {
 }
 
 type CreateContactResponse struct {
-	XMLName   xml.Name                  `xml:"epp"`
-	Result    Result                    `xml:"response>result"`
-	ResData   CreateContactResponseData `xml:"response>resData"`
-	Extension *ResponseExtension        `xml:"response>extension,omitempty"`
-	TrID      TrID                      `xml:"response>trID"`
+	XMLName        xml.Name `xml:"epp"`
+	ResponseStatus `xml:"-"`
+	ResData        CreateContactResponseData `xml:"response>resData"`
+	Extension      *ResponseExtension        `xml:"response>extension,omitempty"`
+	TrID           TrID                      `xml:"response>trID"`
+}
+
+func (r *CreateContactResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return unmarshalResponse(d, start, r)
+}
+
+func (r *CreateContactResponse) payload() any {
+	type createContactResponse CreateContactResponse
+	return (*createContactResponse)(r)
 }
 
 type ResponseExtension struct {
@@ -71,8 +79,16 @@ type Conditions struct {
 }
 
 type Condition struct {
-	Msg     string `xml:"msg"`
-	Details string `xml:"details"`
+	Code       string               `xml:"code,attr"`
+	Severity   string               `xml:"severity,attr"`
+	Msg        string               `xml:"msg"`
+	Details    string               `xml:"details"`
+	Attributes []ConditionAttribute `xml:"attributes>attr"`
+}
+
+type ConditionAttribute struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
 }
 
 type CreateContactResponseData struct {
@@ -87,8 +103,14 @@ type CreateContactData struct {
 }
 
 func (c *Client) CreateContact(contact *Contact) (*CreateContactResponse, error) {
+	return c.CreateContactContext(context.Background(), contact)
+}
+
+func (c *Client) CreateContactContext(ctx context.Context, contact *Contact) (*CreateContactResponse, error) {
+	cmd := command{name: "contact:create", typ: CommandCreate, object: contact.ID}
 	var extension *CommandExtension
 	if contact.Type != "" {
+		cmd.extensions = []string{NamespaceAtExtContact}
 		extension = &CommandExtension{
 			AtExt: &AtContactExtension{
 				XMLName: xml.Name{Local: "at-ext-contact:create"},
@@ -98,6 +120,11 @@ func (c *Client) CreateContact(contact *Contact) (*CreateContactResponse, error)
 		}
 	}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	createReq := CreateContactRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -117,7 +144,7 @@ func (c *Client) CreateContact(contact *Contact) (*CreateContactResponse, error)
 				},
 			},
 			Extension: extension,
-			ClTRID:    generateTransactionID(),
+			ClTRID:    clTRID,
 		},
 	}
 
@@ -128,30 +155,18 @@ func (c *Client) CreateContact(contact *Contact) (*CreateContactResponse, error)
 		return nil, fmt.Errorf("failed to marshal create contact request: %w", err)
 	}
 
-	log.Printf("EPP CreateContact Request XML:\n%s", string(requestXML))
-
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, cmd, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send create contact request: %w", err)
 	}
 
-	log.Printf("EPP CreateContact Response XML:\n%s", string(responseXML))
-
 	var response CreateContactResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal create contact response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		errorMsg := fmt.Sprintf("create contact failed: %s - %s", response.Result.Code, response.Result.Msg)
-
-		if response.Extension != nil && response.Extension.Conditions != nil {
-			for _, condition := range response.Extension.Conditions.Condition {
-				errorMsg += fmt.Sprintf("\nCondition: %s - %s", condition.Msg, condition.Details)
-			}
-		}
-
-		return nil, errors.New(errorMsg)
+		return nil, newEPPError("create contact", reply)
 	}
 
 	return &response, nil
@@ -180,11 +195,20 @@ type ContactInfo struct {
 }
 
 type InfoContactResponse struct {
-	Extension *InfoContactExtension   `xml:"response>extension,omitempty"`
-	XMLName   xml.Name                `xml:"epp"`
-	Result    Result                  `xml:"response>result"`
-	TrID      TrID                    `xml:"response>trID"`
-	ResData   InfoContactResponseData `xml:"response>resData"`
+	Extension      *InfoContactExtension `xml:"response>extension,omitempty"`
+	XMLName        xml.Name              `xml:"epp"`
+	ResponseStatus `xml:"-"`
+	TrID           TrID                    `xml:"response>trID"`
+	ResData        InfoContactResponseData `xml:"response>resData"`
+}
+
+func (r *InfoContactResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return unmarshalResponse(d, start, r)
+}
+
+func (r *InfoContactResponse) payload() any {
+	type infoContactResponse InfoContactResponse
+	return (*infoContactResponse)(r)
 }
 
 type InfoContactExtension struct {
@@ -363,6 +387,15 @@ func normalizeContactPostalInfo(pi *ContactPostalInfo) {
 }
 
 func (c *Client) InfoContact(contactID string) (*InfoContactResponse, error) {
+	return c.InfoContactContext(context.Background(), contactID)
+}
+
+func (c *Client) InfoContactContext(ctx context.Context, contactID string) (*InfoContactResponse, error) {
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	infoReq := InfoContactRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -375,7 +408,7 @@ func (c *Client) InfoContact(contactID string) (*InfoContactResponse, error) {
 					ID:      contactID,
 				},
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -384,18 +417,18 @@ func (c *Client) InfoContact(contactID string) (*InfoContactResponse, error) {
 		return nil, fmt.Errorf("failed to marshal info contact request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "contact:info", typ: CommandInfo, object: contactID, idempotent: true}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send info contact request: %w", err)
 	}
 
 	var response InfoContactResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal info contact response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("info contact failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("info contact", reply)
 	}
 
 	return &response, nil
@@ -466,8 +499,20 @@ func (c *Client) UpdateContact(
 	rem *ContactUpdateRem,
 	chg *ContactUpdateChg,
 ) (*Response, error) {
+	return c.UpdateContactContext(context.Background(), contactID, add, rem, chg)
+}
+
+func (c *Client) UpdateContactContext(
+	ctx context.Context,
+	contactID string,
+	add *ContactUpdateAdd,
+	rem *ContactUpdateRem,
+	chg *ContactUpdateChg,
+) (*Response, error) {
+	cmd := command{name: "contact:update", typ: CommandUpdate, object: contactID}
 	var extension *ContactUpdateExtension
 	if chg != nil && chg.Type != "" {
+		cmd.extensions = []string{NamespaceAtExtContact}
 		extension = &ContactUpdateExtension{
 			Update: &AtContactUpdateExtension{
 				XMLName: xml.Name{Local: "at-ext-contact:update"},
@@ -479,6 +524,11 @@ func (c *Client) UpdateContact(
 		}
 	}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	updateReq := UpdateContactRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -495,7 +545,7 @@ func (c *Client) UpdateContact(
 				},
 			},
 			Extension: extension,
-			ClTRID:    generateTransactionID(),
+			ClTRID:    clTRID,
 		},
 	}
 
@@ -506,17 +556,17 @@ func (c *Client) UpdateContact(
 		return nil, fmt.Errorf("failed to marshal update contact request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, cmd, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send update contact request: %w", err)
 	}
 
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal update contact response: %w", err)
 	}
 
 	if !ierr.IsSuccessCode(response.Result.Code) {
-		return nil, ierr.NewEPPError(response.Result.Code, response.Result.Msg, "update contact operation failed")
+		return nil, newEPPError("update contact", reply)
 	}
 
 	return &response, nil
@@ -545,6 +595,15 @@ type ContactDeleteData struct {
 }
 
 func (c *Client) DeleteContact(contactID string) (*Response, error) {
+	return c.DeleteContactContext(context.Background(), contactID)
+}
+
+func (c *Client) DeleteContactContext(ctx context.Context, contactID string) (*Response, error) {
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	deleteReq := DeleteContactRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -557,7 +616,7 @@ func (c *Client) DeleteContact(contactID string) (*Response, error) {
 					ID:      contactID,
 				},
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -567,17 +626,17 @@ func (c *Client) DeleteContact(contactID string) (*Response, error) {
 		return nil, fmt.Errorf("failed to marshal delete contact request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "contact:delete", typ: CommandDelete, object: contactID}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send delete contact request: %w", err)
 	}
 
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal delete contact response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("delete contact failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("delete contact", reply)
 	}
 
 	return &response, nil
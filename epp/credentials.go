@@ -0,0 +1,227 @@
+package epp
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Environment variables read by EnvCredentials by default.
+const (
+	DefaultUsernameEnv = "EPP_USERNAME"
+	DefaultPasswordEnv = "EPP_PASSWORD"
+)
+
+// ErrCredentialsReadOnly is returned when a rotated password has to be
+// persisted through a provider that cannot store it.
+var ErrCredentialsReadOnly = errors.New("EPP credential provider cannot store passwords")
+
+// Credentials are the account name and password sent at login.
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// CredentialProvider supplies the credentials for every login, including
+// the re-login of a restored session, so a password rotated by another
+// process is picked up without a restart.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialStore is a CredentialProvider that can persist a new password.
+// StorePassword must replace the stored secret atomically: after it returns,
+// readers see either the old or the new password, never a partial write.
+type CredentialStore interface {
+	CredentialProvider
+	StorePassword(ctx context.Context, password string) error
+}
+
+// StaticCredentials always returns the same credentials. It is used for
+// Config.Username and Config.Password when Config.Credentials is nil.
+type StaticCredentials Credentials
+
+func (s StaticCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	return Credentials(s), nil
+}
+
+// EnvCredentials reads the credentials from environment variables at every
+// login. It cannot store passwords.
+type EnvCredentials struct {
+	UsernameVar string // Defaults to DefaultUsernameEnv
+	PasswordVar string // Defaults to DefaultPasswordEnv
+}
+
+func (e EnvCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	usernameVar, passwordVar := e.UsernameVar, e.PasswordVar
+	if usernameVar == "" {
+		usernameVar = DefaultUsernameEnv
+	}
+	if passwordVar == "" {
+		passwordVar = DefaultPasswordEnv
+	}
+
+	username, ok := os.LookupEnv(usernameVar)
+	if !ok {
+		return Credentials{}, fmt.Errorf("EPP credentials: %s is not set", usernameVar)
+	}
+	password, ok := os.LookupEnv(passwordVar)
+	if !ok {
+		return Credentials{}, fmt.Errorf("EPP credentials: %s is not set", passwordVar)
+	}
+	return Credentials{Username: username, Password: password}, nil
+}
+
+// FileCredentials keeps the credentials as JSON in a file readable only by
+// its owner, e.g. {"username": "...", "password": "..."}.
+type FileCredentials struct {
+	Path string
+}
+
+func (f FileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read EPP credentials: %w", err)
+	}
+	return decodeCredentials(data)
+}
+
+// Save writes credentials to the file, replacing it atomically.
+func (f FileCredentials) Save(credentials Credentials) error {
+	data, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("failed to encode EPP credentials: %w", err)
+	}
+	return writeFileAtomic(f.Path, data)
+}
+
+func (f FileCredentials) StorePassword(ctx context.Context, password string) error {
+	credentials, err := f.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+	credentials.Password = password
+	return f.Save(credentials)
+}
+
+// EncryptedFileCredentials keeps the credentials as JSON sealed with
+// AES-256-GCM, so the file alone does not reveal the password. The key has
+// to come from elsewhere, e.g. a secrets manager or KMS.
+type EncryptedFileCredentials struct {
+	path string
+	aead cipher.AEAD
+}
+
+// NewEncryptedFileCredentials opens the encrypted credential file at path
+// with a 32-byte key. The file need not exist until Save is called.
+func NewEncryptedFileCredentials(path string, key []byte) (*EncryptedFileCredentials, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("EPP credential key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EPP credential cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EPP credential cipher: %w", err)
+	}
+	return &EncryptedFileCredentials{path: path, aead: aead}, nil
+}
+
+func (e *EncryptedFileCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	data, err := os.ReadFile(e.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read EPP credentials: %w", err)
+	}
+
+	nonceSize := e.aead.NonceSize()
+	if len(data) < nonceSize {
+		return Credentials{}, errors.New("failed to decrypt EPP credentials: file too short")
+	}
+	plaintext, err := e.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to decrypt EPP credentials: %w", err)
+	}
+	return decodeCredentials(plaintext)
+}
+
+// Save encrypts credentials with a fresh nonce and replaces the file
+// atomically.
+func (e *EncryptedFileCredentials) Save(credentials Credentials) error {
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return fmt.Errorf("failed to encode EPP credentials: %w", err)
+	}
+
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to encrypt EPP credentials: %w", err)
+	}
+	return writeFileAtomic(e.path, e.aead.Seal(nonce, nonce, plaintext, nil))
+}
+
+func (e *EncryptedFileCredentials) StorePassword(ctx context.Context, password string) error {
+	credentials, err := e.Credentials(ctx)
+	if err != nil {
+		return err
+	}
+	credentials.Password = password
+	return e.Save(credentials)
+}
+
+func decodeCredentials(data []byte) (Credentials, error) {
+	var credentials Credentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode EPP credentials: %w", err)
+	}
+	if credentials.Username == "" || credentials.Password == "" {
+		return Credentials{}, errors.New("EPP credentials incomplete: username and password are required")
+	}
+	return credentials, nil
+}
+
+// writeFileAtomic replaces path with data through a synced temporary file
+// in the same directory and a rename.
+func writeFileAtomic(path string, data []byte) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write EPP credentials: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := tmp.Chmod(0o600); err != nil {
+		return fmt.Errorf("failed to write EPP credentials: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write EPP credentials: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to write EPP credentials: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write EPP credentials: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write EPP credentials: %w", err)
+	}
+
+	// Make the rename itself durable.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
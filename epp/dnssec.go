@@ -1,6 +1,7 @@
 package epp
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 )
@@ -63,8 +64,14 @@ type SecDNSUpdateChg struct {
 }
 
 func (c *Client) CreateDomainWithDNSSEC(domain Domain, dsRecords []DNSSECData) (*CreateDomainResponse, error) {
+	return c.CreateDomainWithDNSSECContext(context.Background(), domain, dsRecords)
+}
+
+func (c *Client) CreateDomainWithDNSSECContext(ctx context.Context, domain Domain, dsRecords []DNSSECData) (*CreateDomainResponse, error) {
+	cmd := command{name: "domain:create", typ: CommandCreate, object: domain.Name}
 	var extension *DNSSECExtension
 	if len(dsRecords) > 0 {
+		cmd.extensions = []string{NamespaceSecDNS}
 		extension = &DNSSECExtension{
 			SecDNS: &SecDNSData{
 				XMLName: xml.Name{Local: "secDNS:create"},
@@ -91,6 +98,11 @@ func (c *Client) CreateDomainWithDNSSEC(domain Domain, dsRecords []DNSSECData) (
 	// Convert authInfo to proper structure
 	authInfo := &CreateDomainAuthInfo{Pw: domain.AuthInfo}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	createReq := CreateDomainRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -108,7 +120,7 @@ func (c *Client) CreateDomainWithDNSSEC(domain Domain, dsRecords []DNSSECData) (
 				},
 			},
 			Extension: extension,
-			ClTRID:    generateTransactionID(),
+			ClTRID:    clTRID,
 		},
 	}
 
@@ -117,24 +129,28 @@ func (c *Client) CreateDomainWithDNSSEC(domain Domain, dsRecords []DNSSECData) (
 		return nil, fmt.Errorf("failed to marshal create domain with DNSSEC request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, cmd, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send create domain with DNSSEC request: %w", err)
 	}
 
 	var response CreateDomainResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal create domain with DNSSEC response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("create domain with DNSSEC failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("create domain with DNSSEC", reply)
 	}
 
 	return &response, nil
 }
 
 func (c *Client) UpdateDomainDNSSEC(domainName string, add, rem, chg []DNSSECData) (*Response, error) {
+	return c.UpdateDomainDNSSECContext(context.Background(), domainName, add, rem, chg)
+}
+
+func (c *Client) UpdateDomainDNSSECContext(ctx context.Context, domainName string, add, rem, chg []DNSSECData) (*Response, error) {
 	var secDNSUpdate *SecDNSUpdate
 
 	if len(add) > 0 || len(rem) > 0 || len(chg) > 0 {
@@ -158,6 +174,11 @@ func (c *Client) UpdateDomainDNSSEC(domainName string, add, rem, chg []DNSSECDat
 		return nil, fmt.Errorf("at least one DNSSEC update operation is required")
 	}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	updateReq := UpdateDomainRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -173,7 +194,7 @@ func (c *Client) UpdateDomainDNSSEC(domainName string, add, rem, chg []DNSSECDat
 			Extension: &DNSSECExtension{
 				SecDNSUpdate: secDNSUpdate,
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -182,18 +203,18 @@ func (c *Client) UpdateDomainDNSSEC(domainName string, add, rem, chg []DNSSECDat
 		return nil, fmt.Errorf("failed to marshal update domain DNSSEC request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "domain:update", typ: CommandUpdate, object: domainName, extensions: []string{NamespaceSecDNS}}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send update domain DNSSEC request: %w", err)
 	}
 
 	var response Response
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal update domain DNSSEC response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("update domain DNSSEC failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("update domain DNSSEC", reply)
 	}
 
 	return &response, nil
@@ -1,14 +1,20 @@
 package epp
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
+	"strings"
 
 	"github.com/ParadoxTR/epp-at-go/internal/errors"
 	"github.com/ParadoxTR/epp-at-go/internal/validator"
 )
 
 func (c *Client) CheckDomain(domains []string) (*CheckDomainResponse, error) {
+	return c.CheckDomainContext(context.Background(), domains)
+}
+
+func (c *Client) CheckDomainContext(ctx context.Context, domains []string) (*CheckDomainResponse, error) {
 	if len(domains) == 0 {
 		return nil, fmt.Errorf("at least one domain name is required")
 	}
@@ -19,6 +25,11 @@ func (c *Client) CheckDomain(domains []string) (*CheckDomainResponse, error) {
 		}
 	}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	checkReq := CheckDomainRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -31,7 +42,7 @@ func (c *Client) CheckDomain(domains []string) (*CheckDomainResponse, error) {
 					Names:   domains,
 				},
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -40,18 +51,18 @@ func (c *Client) CheckDomain(domains []string) (*CheckDomainResponse, error) {
 		return nil, fmt.Errorf("failed to marshal domain check request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "domain:check", typ: CommandCheck, object: strings.Join(domains, ","), idempotent: true}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send domain check request: %w", err)
 	}
 
 	var response CheckDomainResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal domain check response: %w", err)
 	}
 
 	if !errors.IsSuccessCode(response.Result.Code) {
-		return nil, errors.NewEPPError(response.Result.Code, response.Result.Msg, "domain check operation failed")
+		return nil, newEPPError("domain check", reply)
 	}
 
 	return &response, nil
@@ -109,11 +120,20 @@ type CreateDomainAuthInfo struct {
 }
 
 type CreateDomainResponse struct {
-	XMLName   xml.Name                 `xml:"epp"`
-	Result    Result                   `xml:"response>result"`
-	ResData   CreateDomainResponseData `xml:"response>resData"`
-	Extension *DomainInfoExtension     `xml:"response>extension,omitempty"`
-	TrID      TrID                     `xml:"response>trID"`
+	XMLName        xml.Name `xml:"epp"`
+	ResponseStatus `xml:"-"`
+	ResData        CreateDomainResponseData `xml:"response>resData"`
+	Extension      *DomainInfoExtension     `xml:"response>extension,omitempty"`
+	TrID           TrID                     `xml:"response>trID"`
+}
+
+func (r *CreateDomainResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return unmarshalResponse(d, start, r)
+}
+
+func (r *CreateDomainResponse) payload() any {
+	type createDomainResponse CreateDomainResponse
+	return (*createDomainResponse)(r)
 }
 
 type CreateDomainResponseData struct {
@@ -129,6 +149,10 @@ type CreateDomainData struct {
 }
 
 func (c *Client) CreateDomain(domain Domain) (*CreateDomainResponse, error) {
+	return c.CreateDomainContext(context.Background(), domain)
+}
+
+func (c *Client) CreateDomainContext(ctx context.Context, domain Domain) (*CreateDomainResponse, error) {
 	// Convert nameservers to proper structure (NIC.at requires hostAttr format)
 	var nameservers *CreateDomainNameservers
 	if len(domain.Nameservers) > 0 {
@@ -146,6 +170,11 @@ func (c *Client) CreateDomain(domain Domain) (*CreateDomainResponse, error) {
 	// Convert authInfo to proper structure
 	authInfo := &CreateDomainAuthInfo{Pw: domain.AuthInfo}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	createReq := CreateDomainRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -162,7 +191,7 @@ func (c *Client) CreateDomain(domain Domain) (*CreateDomainResponse, error) {
 					AuthInfo:    authInfo,
 				},
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -171,18 +200,18 @@ func (c *Client) CreateDomain(domain Domain) (*CreateDomainResponse, error) {
 		return nil, fmt.Errorf("failed to marshal create domain request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "domain:create", typ: CommandCreate, object: domain.Name}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send create domain request: %w", err)
 	}
 
 	var response CreateDomainResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal create domain response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("create domain failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("create domain", reply)
 	}
 
 	return &response, nil
@@ -221,11 +250,20 @@ type DomainAuthInfo struct {
 }
 
 type InfoDomainResponse struct {
-	XMLName   xml.Name               `xml:"epp"`
-	Result    Result                 `xml:"response>result"`
-	ResData   InfoDomainResponseData `xml:"response>resData"`
-	Extension *DomainInfoExtension   `xml:"response>extension,omitempty"`
-	TrID      TrID                   `xml:"response>trID"`
+	XMLName        xml.Name `xml:"epp"`
+	ResponseStatus `xml:"-"`
+	ResData        InfoDomainResponseData `xml:"response>resData"`
+	Extension      *DomainInfoExtension   `xml:"response>extension,omitempty"`
+	TrID           TrID                   `xml:"response>trID"`
+}
+
+func (r *InfoDomainResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return unmarshalResponse(d, start, r)
+}
+
+func (r *InfoDomainResponse) payload() any {
+	type infoDomainResponse InfoDomainResponse
+	return (*infoDomainResponse)(r)
 }
 
 type DomainInfoExtension struct {
@@ -324,6 +362,15 @@ type DomainHostAddr struct {
 }
 
 func (c *Client) InfoDomain(domainName string) (*InfoDomainResponse, error) {
+	return c.InfoDomainContext(context.Background(), domainName)
+}
+
+func (c *Client) InfoDomainContext(ctx context.Context, domainName string) (*InfoDomainResponse, error) {
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	infoReq := InfoDomainRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -340,7 +387,7 @@ func (c *Client) InfoDomain(domainName string) (*InfoDomainResponse, error) {
 					AuthInfo: &DomainAuthInfo{Pw: ""},
 				},
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -349,18 +396,18 @@ func (c *Client) InfoDomain(domainName string) (*InfoDomainResponse, error) {
 		return nil, fmt.Errorf("failed to marshal info domain request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "domain:info", typ: CommandInfo, object: domainName, idempotent: true}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send info domain request: %w", err)
 	}
 
 	var response InfoDomainResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal info domain response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("info domain failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("info domain", reply)
 	}
 
 	return &response, nil
@@ -428,10 +475,19 @@ type UpdateDomainHostAddr struct {
 }
 
 func (c *Client) UpdateDomain(domainName string, add *DomainUpdateAdd, rem *DomainUpdateRem, chg *DomainUpdateChg) (*Response, error) {
+	return c.UpdateDomainContext(context.Background(), domainName, add, rem, chg)
+}
+
+func (c *Client) UpdateDomainContext(ctx context.Context, domainName string, add *DomainUpdateAdd, rem *DomainUpdateRem, chg *DomainUpdateChg) (*Response, error) {
 	if add == nil && rem == nil && chg == nil {
 		return nil, fmt.Errorf("at least one domain update operation is required")
 	}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	updateReq := UpdateDomainRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -447,7 +503,7 @@ func (c *Client) UpdateDomain(domainName string, add *DomainUpdateAdd, rem *Doma
 					Chg:     chg,
 				},
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -456,24 +512,28 @@ func (c *Client) UpdateDomain(domainName string, add *DomainUpdateAdd, rem *Doma
 		return nil, fmt.Errorf("failed to marshal update domain request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "domain:update", typ: CommandUpdate, object: domainName}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send update domain request: %w", err)
 	}
 
 	var response Response
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal update domain response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("update domain failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("update domain", reply)
 	}
 
 	return &response, nil
 }
 
 func (c *Client) UpdateDomainNameservers(domainName string, add, remove []UpdateDomainHostAttr) (*Response, error) {
+	return c.UpdateDomainNameserversContext(context.Background(), domainName, add, remove)
+}
+
+func (c *Client) UpdateDomainNameserversContext(ctx context.Context, domainName string, add, remove []UpdateDomainHostAttr) (*Response, error) {
 	var addNS *UpdateDomainNameservers
 	if len(add) > 0 {
 		addNS = &UpdateDomainNameservers{HostAttrs: add}
@@ -494,7 +554,8 @@ func (c *Client) UpdateDomainNameservers(domainName string, add, remove []Update
 		remUpdate = &DomainUpdateRem{Ns: remNS}
 	}
 
-	return c.UpdateDomain(
+	return c.UpdateDomainContext(
+		ctx,
 		domainName,
 		addUpdate,
 		remUpdate,
@@ -520,7 +581,11 @@ type DeleteDomain struct {
 }
 
 func (c *Client) DeleteDomain(domainName string) (*Response, error) {
-	return c.DeleteDomainWithSchedule(domainName, "now")
+	return c.DeleteDomainContext(context.Background(), domainName)
+}
+
+func (c *Client) DeleteDomainContext(ctx context.Context, domainName string) (*Response, error) {
+	return c.DeleteDomainWithScheduleContext(ctx, domainName, "now")
 }
 
 type DeleteDomainData struct {
@@ -541,10 +606,19 @@ type AtDomainDeleteExtension struct {
 }
 
 func (c *Client) DeleteDomainWithSchedule(domainName, scheduleDate string) (*Response, error) {
+	return c.DeleteDomainWithScheduleContext(context.Background(), domainName, scheduleDate)
+}
+
+func (c *Client) DeleteDomainWithScheduleContext(ctx context.Context, domainName, scheduleDate string) (*Response, error) {
 	if scheduleDate != "" && scheduleDate != "now" && scheduleDate != "expiration" {
 		return nil, fmt.Errorf("invalid domain delete schedule date: %s", scheduleDate)
 	}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	deleteReq := DeleteDomainRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -564,7 +638,7 @@ func (c *Client) DeleteDomainWithSchedule(domainName, scheduleDate string) (*Res
 					ScheduleDate: scheduleDate,
 				},
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -573,18 +647,18 @@ func (c *Client) DeleteDomainWithSchedule(domainName, scheduleDate string) (*Res
 		return nil, fmt.Errorf("failed to marshal delete domain request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "domain:delete", typ: CommandDelete, object: domainName, extensions: []string{NamespaceAtExtDomain}}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send delete domain request: %w", err)
 	}
 
 	var response Response
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal delete domain response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("delete domain failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("delete domain", reply)
 	}
 
 	return &response, nil
@@ -618,11 +692,20 @@ type TransferAuthInfo struct {
 }
 
 type TransferDomainResponse struct {
-	XMLName   xml.Name                   `xml:"epp"`
-	Result    Result                     `xml:"response>result"`
-	ResData   TransferDomainResponseData `xml:"response>resData"`
-	Extension *TransferExtension         `xml:"response>extension"`
-	TrID      TrID                       `xml:"response>trID"`
+	XMLName        xml.Name `xml:"epp"`
+	ResponseStatus `xml:"-"`
+	ResData        TransferDomainResponseData `xml:"response>resData"`
+	Extension      *TransferExtension         `xml:"response>extension"`
+	TrID           TrID                       `xml:"response>trID"`
+}
+
+func (r *TransferDomainResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return unmarshalResponse(d, start, r)
+}
+
+func (r *TransferDomainResponse) payload() any {
+	type transferDomainResponse TransferDomainResponse
+	return (*transferDomainResponse)(r)
 }
 
 type TransferExtension struct {
@@ -656,23 +739,40 @@ type TransferDomainData struct {
 }
 
 func (c *Client) TransferRequestDomain(domainName, authInfo string) (*TransferDomainResponse, error) {
-	return c.transferDomain(domainName, "request", authInfo)
+	return c.TransferRequestDomainContext(context.Background(), domainName, authInfo)
+}
+
+func (c *Client) TransferRequestDomainContext(ctx context.Context, domainName, authInfo string) (*TransferDomainResponse, error) {
+	return c.transferDomain(ctx, domainName, "request", authInfo)
 }
 
 func (c *Client) TransferQueryDomain(domainName string) (*TransferDomainResponse, error) {
-	return c.transferDomain(domainName, "query", "")
+	return c.TransferQueryDomainContext(context.Background(), domainName)
+}
+
+func (c *Client) TransferQueryDomainContext(ctx context.Context, domainName string) (*TransferDomainResponse, error) {
+	return c.transferDomain(ctx, domainName, "query", "")
 }
 
 func (c *Client) TransferCancelDomain(domainName string) (*TransferDomainResponse, error) {
-	return c.transferDomain(domainName, "cancel", "")
+	return c.TransferCancelDomainContext(context.Background(), domainName)
 }
 
-func (c *Client) transferDomain(domainName, operation, authInfo string) (*TransferDomainResponse, error) {
+func (c *Client) TransferCancelDomainContext(ctx context.Context, domainName string) (*TransferDomainResponse, error) {
+	return c.transferDomain(ctx, domainName, "cancel", "")
+}
+
+func (c *Client) transferDomain(ctx context.Context, domainName, operation, authInfo string) (*TransferDomainResponse, error) {
 	var authInfoStruct *TransferAuthInfo
 	if authInfo != "" {
 		authInfoStruct = &TransferAuthInfo{Pw: authInfo}
 	}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	transferReq := TransferDomainRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -686,7 +786,7 @@ func (c *Client) transferDomain(domainName, operation, authInfo string) (*Transf
 					AuthInfo: authInfoStruct,
 				},
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -695,23 +795,27 @@ func (c *Client) transferDomain(domainName, operation, authInfo string) (*Transf
 		return nil, fmt.Errorf("failed to marshal transfer domain request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "domain:transfer", typ: CommandTransfer, object: domainName, idempotent: operation == "query"}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send transfer domain request: %w", err)
 	}
 
 	var response TransferDomainResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal transfer domain response: %w", err)
 	}
 
 	if response.Result.Code != "1000" && response.Result.Code != "1001" {
-		return nil, fmt.Errorf("transfer domain failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("transfer domain", reply)
 	}
 
 	return &response, nil
 }
 
 func (c *Client) WithdrawDomain(domainName string) (*Response, error) {
-	return c.withdrawDomain(domainName, nil)
+	return c.WithdrawDomainContext(context.Background(), domainName)
+}
+
+func (c *Client) WithdrawDomainContext(ctx context.Context, domainName string) (*Response, error) {
+	return c.withdrawDomain(ctx, domainName, nil)
 }
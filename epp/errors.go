@@ -0,0 +1,102 @@
+package epp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	ierr "github.com/ParadoxTR/epp-at-go/internal/errors"
+)
+
+// Sentinel errors matched by errors.Is against an *EPPError carrying the
+// corresponding result code.
+var (
+	ErrObjectExists                 = errors.New("EPP object exists")
+	ErrObjectDoesNotExist           = errors.New("EPP object does not exist")
+	ErrAuthentication               = errors.New("EPP authentication error")
+	ErrAuthorization                = errors.New("EPP authorization error")
+	ErrObjectStatusProhibitsOp      = errors.New("EPP object status prohibits operation")
+	ErrObjectAssociationProhibitsOp = errors.New("EPP object association prohibits operation")
+	ErrParameterPolicy              = errors.New("EPP parameter value policy error")
+	ErrObjectPendingTransfer        = errors.New("EPP object pending transfer")
+	ErrBillingFailure               = errors.New("EPP billing failure")
+	ErrSessionLimitExceeded         = errors.New("EPP session limit exceeded")
+)
+
+var codeSentinels = map[string]error{
+	ierr.CodeObjectExists:                     ErrObjectExists,
+	ierr.CodeObjectDoesNotExist:               ErrObjectDoesNotExist,
+	ierr.CodeAuthenticationError:              ErrAuthentication,
+	ierr.CodeAuthenticationErrorServerClosing: ErrAuthentication,
+	ierr.CodeAuthorizationError:               ErrAuthorization,
+	ierr.CodeInvalidAuthorizationInfo:         ErrAuthorization,
+	ierr.CodeObjectStatusProhibitsOp:          ErrObjectStatusProhibitsOp,
+	ierr.CodeObjectAssociationProhibitsOp:     ErrObjectAssociationProhibitsOp,
+	ierr.CodeParameterValuePolicyError:        ErrParameterPolicy,
+	ierr.CodeObjectPendingTransfer:            ErrObjectPendingTransfer,
+	ierr.CodeBillingFailure:                   ErrBillingFailure,
+	ierr.CodeSessionLimitExceeded:             ErrSessionLimitExceeded,
+}
+
+// EPPError is returned by every command the registry answered with a
+// failure result code.
+type EPPError struct {
+	Command    string      // Operation that failed, e.g. "info domain"
+	Code       string      // EPP result code
+	Message    string      // Result message
+	ClTRID     string      // Client transaction ID
+	SvTRID     string      // Server transaction ID
+	Reasons    []ExtValue  // extValue details of all results
+	Conditions []Condition // nic.at conditions extension
+}
+
+func (e *EPPError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s failed: %s - %s", e.Command, e.Code, e.Message)
+	for _, reason := range e.Reasons {
+		fmt.Fprintf(&b, "; reason: %s", reason.Reason)
+		if value := strings.TrimSpace(reason.Value.XML); value != "" {
+			fmt.Fprintf(&b, " (%s)", value)
+		}
+	}
+	for _, condition := range e.Conditions {
+		fmt.Fprintf(&b, "; condition: %s - %s", condition.Msg, condition.Details)
+	}
+	return b.String()
+}
+
+func (e *EPPError) Is(target error) bool {
+	sentinel, ok := codeSentinels[e.Code]
+	return ok && sentinel == target
+}
+
+type ExtValue struct {
+	Value  ExtValueElement `xml:"value"`
+	Reason string          `xml:"reason"`
+}
+
+// ExtValueElement holds the offending element as echoed by the server, e.g.
+// <domain:name>example.at</domain:name>.
+type ExtValueElement struct {
+	XML string `xml:",innerxml"`
+}
+
+func newEPPError(command string, reply *reply) *EPPError {
+	status := reply.status
+	if len(status.Results) == 0 {
+		return &EPPError{Command: command, Message: "unparseable response"}
+	}
+
+	e := &EPPError{
+		Command:    command,
+		Code:       status.Result.Code,
+		Message:    status.Result.Msg,
+		ClTRID:     reply.trID.ClTRID,
+		SvTRID:     reply.trID.SvTRID,
+		Conditions: status.Conditions,
+	}
+	for _, result := range status.Results {
+		e.Reasons = append(e.Reasons, result.ExtValues...)
+	}
+	return e
+}
@@ -1,8 +1,11 @@
 package epp
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
+	"slices"
+	"time"
 )
 
 type HelloResponse struct {
@@ -80,26 +83,114 @@ type Retention struct {
 }
 
 func (c *Client) Hello() (*HelloResponse, error) {
-	helloReq := HelloRequest{
-		XMLName: xml.Name{Local: "epp"},
-		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
-		Hello:   struct{}{},
-	}
+	return c.HelloContext(context.Background())
+}
 
-	requestXML, err := xml.Marshal(helloReq)
+func (c *Client) HelloContext(ctx context.Context) (*HelloResponse, error) {
+	requestXML, err := helloRequest()
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal hello request: %w", err)
+		return nil, err
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, helloCommand, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send hello request: %w", err)
 	}
 
+	receivedAt := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.recordGreeting(reply.frame, receivedAt)
+}
+
+// Greeting returns the greeting received on Connect, refreshed by every
+// Hello, or nil before the first connection.
+func (c *Client) Greeting() *Greeting {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.greeting == nil {
+		return nil
+	}
+	return c.greeting.clone()
+}
+
+// clone returns a deep copy, so that callers cannot modify the service lists
+// the session negotiates its login with.
+func (g *Greeting) clone() *Greeting {
+	clone := *g
+	clone.SvcMenu.Version = slices.Clone(g.SvcMenu.Version)
+	clone.SvcMenu.Lang = slices.Clone(g.SvcMenu.Lang)
+	clone.SvcMenu.ObjURI = slices.Clone(g.SvcMenu.ObjURI)
+	clone.SvcMenu.SvcExt = slices.Clone(g.SvcMenu.SvcExt)
+	if g.SvcMenu.SvcExtension != nil {
+		clone.SvcMenu.SvcExtension = &GreetingServiceExtension{ExtURI: slices.Clone(g.SvcMenu.SvcExtension.ExtURI)}
+	}
+	clone.DCP.Statement = slices.Clone(g.DCP.Statement)
+	return &clone
+}
+
+// ClockSkew estimates how far the server clock is ahead of the local clock
+// (negative when behind), from svDate of the most recent greeting. The
+// estimate includes the one-way network latency.
+func (c *Client) ClockSkew() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.clockSkew
+}
+
+// recordGreeting parses a greeting frame and stores it on the client. The
+// caller must hold c.mu.
+func (c *Client) recordGreeting(greetingXML []byte, receivedAt time.Time) (*HelloResponse, error) {
 	var response HelloResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal hello response: %w", err)
+	if err := xml.Unmarshal(greetingXML, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal server greeting: %w", err)
+	}
+
+	c.greeting = &response.Greeting
+	if serverTime, err := response.Greeting.ServerTime(); err == nil {
+		c.clockSkew = serverTime.Sub(receivedAt)
 	}
 
 	return &response, nil
 }
+
+// ServerTime parses svDate.
+func (g *Greeting) ServerTime() (time.Time, error) {
+	serverTime, err := time.Parse(time.RFC3339Nano, g.SvDate)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid greeting svDate %q: %w", g.SvDate, err)
+	}
+	return serverTime, nil
+}
+
+// ObjectURIs returns the object namespaces advertised in the service menu.
+func (g *Greeting) ObjectURIs() []string {
+	return g.SvcMenu.ObjURI
+}
+
+// ExtensionURIs returns the extension namespaces advertised in the service
+// menu.
+func (g *Greeting) ExtensionURIs() []string {
+	return g.SvcMenu.SvcExt
+}
+
+var helloCommand = command{name: "hello", typ: CommandHello, idempotent: true}
+
+func helloRequest() ([]byte, error) {
+	helloReq := HelloRequest{
+		XMLName: xml.Name{Local: "epp"},
+		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
+		Hello:   struct{}{},
+	}
+
+	requestXML, err := xml.Marshal(helloReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hello request: %w", err)
+	}
+
+	return requestXML, nil
+}
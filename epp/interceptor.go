@@ -0,0 +1,96 @@
+package epp
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+)
+
+// Exchange describes the frame exchange an interceptor wraps. Command,
+// Type, Object, ClTRID and Idempotent are set before the chain runs;
+// ResultCode and SvTRID are filled in once the innermost invoker has read a
+// response.
+type Exchange struct {
+	Command    string      // EPP element, e.g. "domain:check", or "login", "logout", "hello", "greeting"
+	Type       CommandType // Command class, used for deadlines and rate limits
+	Object     string      // Domain name, contact ID or poll message ID, if any
+	ClTRID     string      // Client transaction ID of the request, empty for hello and the greeting
+	Idempotent bool        // Safe to replay when the response was lost
+	ResultCode string      // First result code of the response; empty for greetings and failed exchanges
+	SvTRID     string      // Server transaction ID of the response
+}
+
+// Invoker performs the rest of a frame exchange: it sends request, which is
+// nil when only the greeting is read, and returns the response frame.
+type Invoker func(ctx context.Context, exchange *Exchange, request []byte) ([]byte, error)
+
+// Interceptor wraps every frame exchange of a Client, including the
+// greeting, login, logout, keepalive hellos and the attempts of a retried
+// command. It may inspect or rewrite the request and response, observe
+// errors, or fail the exchange without calling next. Interceptors run while
+// the session lock is held, so they must not call methods of the same
+// Client.
+type Interceptor func(ctx context.Context, exchange *Exchange, request []byte, next Invoker) ([]byte, error)
+
+// Use appends interceptors to the chain. Interceptors registered first run
+// outermost.
+func (c *Client) Use(interceptors ...Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interceptors = append(c.interceptors, interceptors...)
+}
+
+// exchange runs one frame exchange through the interceptor chain. The caller
+// must hold c.mu.
+func (c *Client) exchange(ctx context.Context, cmd command, request []byte) (*reply, error) {
+	ex := &Exchange{
+		Command:    cmd.name,
+		Type:       cmd.typ,
+		Object:     cmd.object,
+		ClTRID:     requestClTRID(request),
+		Idempotent: cmd.idempotent,
+	}
+
+	var transmitted *reply
+	invoke := func(ctx context.Context, ex *Exchange, request []byte) ([]byte, error) {
+		reply, err := c.transmit(ctx, cmd, request)
+		if err != nil {
+			return nil, err
+		}
+		transmitted = reply
+		ex.ResultCode, ex.SvTRID = reply.code(), reply.trID.SvTRID
+		return reply.frame, nil
+	}
+
+	interceptors := append(c.interceptors[:len(c.interceptors):len(c.interceptors)], c.wire...)
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoke
+		invoke = func(ctx context.Context, ex *Exchange, request []byte) ([]byte, error) {
+			return interceptor(ctx, ex, request, next)
+		}
+	}
+
+	response, err := invoke(ctx, ex, request)
+	if err != nil {
+		return nil, err
+	}
+	if transmitted == nil || !bytes.Equal(response, transmitted.frame) {
+		// An interceptor answered itself or rewrote the response.
+		return newReply(cmd, response), nil
+	}
+	return transmitted, nil
+}
+
+func requestClTRID(request []byte) string {
+	if request == nil {
+		return ""
+	}
+	var envelope struct {
+		ClTRID string `xml:"command>clTRID"`
+	}
+	if err := xml.Unmarshal(request, &envelope); err != nil {
+		return ""
+	}
+	return envelope.ClTRID
+}
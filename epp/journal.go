@@ -0,0 +1,355 @@
+package epp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Journal entry directions.
+const (
+	JournalOutbound = "out" // Frame sent to the registry
+	JournalInbound  = "in"  // Frame received from the registry
+)
+
+// Journal defaults.
+const (
+	DefaultJournalMaxSize    = 100 << 20 // Bytes per journal file before it is rotated
+	DefaultJournalMaxBackups = 10        // Rotated files kept next to the current one
+)
+
+// JournalEntry is one frame of the wire journal, stored as a single JSON
+// line.
+type JournalEntry struct {
+	Time       time.Time `json:"time"`
+	Direction  string    `json:"direction"`        // JournalOutbound or JournalInbound
+	Command    string    `json:"command"`          // EPP element, e.g. "domain:create", "login" or "greeting"
+	Object     string    `json:"object,omitempty"` // Domain name, contact ID or poll message ID, if any; checks list their names separated by ","
+	ClTRID     string    `json:"clTRID,omitempty"`
+	SvTRID     string    `json:"svTRID,omitempty"`
+	ResultCode string    `json:"code,omitempty"`
+	Frame      string    `json:"frame"` // Redacted XML as sent or received
+}
+
+// JournalSink receives every frame a Client sends and receives. It must be
+// safe for concurrent use when it is shared, e.g. by the sessions of a Pool.
+type JournalSink interface {
+	Append(entry JournalEntry) error
+}
+
+// JournalOptions controls rotation of a Journal.
+type JournalOptions struct {
+	MaxSize    int64 // Bytes per file before rotation, defaults to DefaultJournalMaxSize
+	MaxBackups int   // Rotated files kept as path.1 (newest) to path.N, defaults to DefaultJournalMaxBackups
+}
+
+// JournalQuery selects journal entries. Empty fields match everything;
+// non-empty fields must all match.
+type JournalQuery struct {
+	Domain        string    // Domain name of domain commands, including checks of several names, matched case-insensitively
+	ContactID     string    // Contact ID of contact commands
+	TransactionID string    // Client or server transaction ID
+	Since         time.Time // Only entries at or after this time
+	Until         time.Time // Only entries before this time
+}
+
+// Journal is a JournalSink that appends JSON Lines to a file and rotates it
+// once it reaches MaxSize. It is safe for concurrent use.
+type Journal struct {
+	path    string
+	options JournalOptions
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenJournal opens or creates the journal file at path for appending.
+func OpenJournal(path string, options JournalOptions) (*Journal, error) {
+	if options.MaxSize <= 0 {
+		options.MaxSize = DefaultJournalMaxSize
+	}
+	if options.MaxBackups <= 0 {
+		options.MaxBackups = DefaultJournalMaxBackups
+	}
+
+	j := &Journal{path: path, options: options}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *Journal) open() error {
+	file, err := os.OpenFile(j.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open EPP journal: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open EPP journal: %w", err)
+	}
+
+	j.file = file
+	j.size = info.Size()
+	return nil
+}
+
+// Append writes entry as one line, rotating the file first when the line
+// would exceed MaxSize.
+func (j *Journal) Append(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode EPP journal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("EPP journal is closed")
+	}
+	if j.size > 0 && j.size+int64(len(line)) > j.options.MaxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write EPP journal entry: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts path.N-1 to path.N, ..., path to path.1 and starts a new
+// file. The caller must hold j.mu.
+func (j *Journal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate EPP journal: %w", err)
+	}
+	j.file = nil
+
+	for i := j.options.MaxBackups - 1; i >= 1; i-- {
+		err := os.Rename(backupPath(j.path, i), backupPath(j.path, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate EPP journal: %w", err)
+		}
+	}
+	if err := os.Rename(j.path, backupPath(j.path, 1)); err != nil {
+		return fmt.Errorf("failed to rotate EPP journal: %w", err)
+	}
+
+	return j.open()
+}
+
+// Close closes the current journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// Find returns the entries of this journal, including rotated files, that
+// match query, oldest first. The files are opened while appends are held
+// back, so that a rotation cannot move entries between them, and read
+// afterwards without blocking the sessions.
+func (j *Journal) Find(query JournalQuery) ([]JournalEntry, error) {
+	j.mu.Lock()
+	files, err := openJournalFiles(j.path)
+	j.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return readJournalFiles(files, query)
+}
+
+// ReadJournal returns the entries of the journal at path and its rotated
+// files that match query, oldest first.
+func ReadJournal(path string, query JournalQuery) ([]JournalEntry, error) {
+	files, err := openJournalFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	return readJournalFiles(files, query)
+}
+
+// openJournalFiles opens the journal at path and its rotated files, oldest
+// first.
+func openJournalFiles(path string) ([]*os.File, error) {
+	var files []*os.File
+	for i := maxBackupIndex(path); i >= 0; i-- {
+		file, err := os.Open(backupPath(path, i))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			closeJournalFiles(files)
+			return nil, fmt.Errorf("failed to read EPP journal: %w", err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// readJournalFiles reads and closes files.
+func readJournalFiles(files []*os.File, query JournalQuery) ([]JournalEntry, error) {
+	defer closeJournalFiles(files)
+
+	var entries []JournalEntry
+	for _, file := range files {
+		var err error
+		entries, err = readJournalFile(file, query, entries)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func closeJournalFiles(files []*os.File) {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+func readJournalFile(r io.Reader, query JournalQuery, entries []JournalEntry) ([]JournalEntry, error) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) > 0 {
+			// A line still being appended; it is complete once it ends in
+			// a newline.
+			return entries, nil
+		}
+		if strings.TrimSpace(string(line)) != "" {
+			var entry JournalEntry
+			if jsonErr := json.Unmarshal(line, &entry); jsonErr != nil {
+				return nil, fmt.Errorf("failed to decode EPP journal entry: %w", jsonErr)
+			}
+			if query.matches(entry) {
+				entries = append(entries, entry)
+			}
+		}
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read EPP journal: %w", err)
+		}
+	}
+}
+
+func (q JournalQuery) matches(entry JournalEntry) bool {
+	if q.Domain != "" && !(strings.HasPrefix(entry.Command, "domain:") && containsDomain(entry.Object, q.Domain)) {
+		return false
+	}
+	if q.ContactID != "" && !(strings.HasPrefix(entry.Command, "contact:") && entry.Object == q.ContactID) {
+		return false
+	}
+	if q.TransactionID != "" && entry.ClTRID != q.TransactionID && entry.SvTRID != q.TransactionID {
+		return false
+	}
+	if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !entry.Time.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// containsDomain reports whether the object of a journal entry, a domain
+// name or the names of a check separated by ",", includes domain.
+func containsDomain(object, domain string) bool {
+	for _, name := range strings.Split(object, ",") {
+		if strings.EqualFold(name, domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func backupPath(path string, index int) string {
+	if index == 0 {
+		return path
+	}
+	return path + "." + strconv.Itoa(index)
+}
+
+// maxBackupIndex finds the oldest rotated file, which may lie beyond the
+// current MaxBackups if the journal was written with a larger setting.
+func maxBackupIndex(path string) int {
+	index := 0
+	for {
+		if _, err := os.Stat(backupPath(path, index+1)); err != nil {
+			return index
+		}
+		index++
+	}
+}
+
+// journalInterceptor records each exchange's frames, redacted, in sink. A
+// request that cannot be journaled is not sent; a response that cannot be
+// journaled is still returned and the failure logged.
+func journalInterceptor(sink JournalSink, redactor *redactor, logger *slog.Logger) Interceptor {
+	return func(ctx context.Context, ex *Exchange, request []byte, next Invoker) ([]byte, error) {
+		if request != nil {
+			err := sink.Append(JournalEntry{
+				Time:      time.Now(),
+				Direction: JournalOutbound,
+				Command:   ex.Command,
+				Object:    ex.Object,
+				ClTRID:    ex.ClTRID,
+				Frame:     string(redactor.redact(request)),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("EPP %s not sent: %w", ex.Command, err)
+			}
+		}
+
+		response, err := next(ctx, ex, request)
+		if err != nil {
+			return response, err
+		}
+
+		journalErr := sink.Append(JournalEntry{
+			Time:       time.Now(),
+			Direction:  JournalInbound,
+			Command:    ex.Command,
+			Object:     ex.Object,
+			ClTRID:     ex.ClTRID,
+			SvTRID:     ex.SvTRID,
+			ResultCode: ex.ResultCode,
+			Frame:      string(redactor.redact(response)),
+		})
+		if journalErr != nil && logger != nil {
+			logger.ErrorContext(ctx, "EPP journal write failed",
+				"command", ex.Command,
+				"clTRID", ex.ClTRID,
+				"svTRID", ex.SvTRID,
+				"error", journalErr)
+		}
+
+		return response, nil
+	}
+}
@@ -0,0 +1,41 @@
+package epp
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournalFindMatchesDomainOfCheck(t *testing.T) {
+	journal, err := OpenJournal(filepath.Join(t.TempDir(), "epp.journal"), JournalOptions{MaxSize: 256})
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer journal.Close()
+
+	entries := []JournalEntry{
+		{Command: "domain:check", Object: "one.at,Example.at,two.at", ClTRID: "check"},
+		{Command: "domain:info", Object: "example.at", ClTRID: "info"},
+		{Command: "domain:info", Object: "example.at.example", ClTRID: "other"},
+		{Command: "contact:info", Object: "example.at", ClTRID: "contact"},
+	}
+	for _, entry := range entries {
+		entry.Time = time.Now()
+		entry.Direction = JournalOutbound
+		if err := journal.Append(entry); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+
+	found, err := journal.Find(JournalQuery{Domain: "example.at"})
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	var clTRIDs []string
+	for _, entry := range found {
+		clTRIDs = append(clTRIDs, entry.ClTRID)
+	}
+	if len(clTRIDs) != 2 || clTRIDs[0] != "check" || clTRIDs[1] != "info" {
+		t.Errorf("Find returned %v, want [check info]", clTRIDs)
+	}
+}
@@ -0,0 +1,105 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// keepalive sends hello frames on an otherwise idle session so nic.at does
+// not drop it between bursts of work.
+type keepalive struct {
+	interval time.Duration
+	onError  func(error)
+
+	mu   sync.Mutex
+	stop chan struct{}
+}
+
+func (c *Client) startKeepalive() {
+	k := &c.keepalive
+	if k.interval <= 0 {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.stop != nil {
+		return
+	}
+	k.stop = make(chan struct{})
+	go c.runKeepalive(k.stop)
+}
+
+// stopKeepalive does not wait for the scheduler to exit so that it can be
+// called from the OnKeepaliveError callback. A tick racing with the stop
+// finds the session logged out and does nothing.
+func (c *Client) stopKeepalive() {
+	k := &c.keepalive
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.stop != nil {
+		close(k.stop)
+		k.stop = nil
+	}
+}
+
+func (c *Client) runKeepalive(stop chan struct{}) {
+	interval := c.keepalive.interval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+		}
+
+		next, err := c.keepaliveTick(interval)
+		if err != nil {
+			c.logf(context.Background(), slog.LevelWarn, "EPP keepalive failed", "error", err)
+			if c.keepalive.onError != nil {
+				c.keepalive.onError(err)
+			}
+		}
+		timer.Reset(next)
+	}
+}
+
+// keepaliveTick sends a hello when the session has been idle for a full
+// interval and returns the delay until the next check. A command in flight
+// counts as activity, so the tick skips rather than waits for it. The hello
+// itself goes through HelloContext like any other command.
+func (c *Client) keepaliveTick(interval time.Duration) (time.Duration, error) {
+	if !c.mu.TryLock() {
+		return interval, nil
+	}
+	idle := time.Since(c.lastActivity)
+	usable := c.loggedIn && (c.canReconnect() || (c.conn != nil && !c.poisoned))
+	c.mu.Unlock()
+
+	if idle < interval {
+		return interval - idle, nil
+	}
+	if !usable {
+		return interval, nil
+	}
+
+	_, err := c.HelloContext(context.Background())
+	if errors.Is(err, ErrInvalidState) {
+		// The session was logged out or closed since the check.
+		return interval, nil
+	}
+	if err != nil {
+		return interval, fmt.Errorf("keepalive hello failed: %w", err)
+	}
+
+	return interval, nil
+}
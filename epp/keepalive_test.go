@@ -0,0 +1,137 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// signalTracer reports every span named name on ended once it ends.
+type signalTracer struct {
+	name  string
+	ended chan struct{}
+}
+
+func (t signalTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	return ctx, signalSpan{ended: t.ended, match: name == t.name}
+}
+
+type signalSpan struct {
+	ended chan struct{}
+	match bool
+}
+
+func (s signalSpan) SetAttribute(key string, value any) {}
+func (s signalSpan) RecordError(err error)              {}
+
+func (s signalSpan) End() {
+	if !s.match {
+		return
+	}
+	select {
+	case s.ended <- struct{}{}:
+	default:
+	}
+}
+
+func TestKeepaliveSendsHelloAsCommand(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		served      int
+		intercepted int
+	)
+	transport := testServer(t, func(request string) string {
+		if strings.Contains(request, "<hello") {
+			mu.Lock()
+			served++
+			mu.Unlock()
+			return testGreeting
+		}
+		return testResponse(request, "1000", "")
+	})
+
+	tracer := signalTracer{name: "EPP hello", ended: make(chan struct{}, 16)}
+	client := NewClient(Config{
+		Username:          "user",
+		Password:          "secret",
+		Transport:         transport,
+		KeepaliveInterval: 20 * time.Millisecond,
+		OnKeepaliveError:  func(err error) { t.Errorf("keepalive: %v", err) },
+		Tracer:            tracer,
+		Interceptors: []Interceptor{func(ctx context.Context, ex *Exchange, request []byte, next Invoker) ([]byte, error) {
+			if ex.Command == "hello" {
+				mu.Lock()
+				intercepted++
+				mu.Unlock()
+			}
+			return next(ctx, ex, request)
+		}},
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-tracer.ended:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("saw %d keepalive hello spans, want 2", i)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if served < 2 || intercepted < 2 {
+		t.Errorf("server answered %d hellos and interceptor saw %d, want at least 2 each", served, intercepted)
+	}
+}
+
+func TestKeepaliveReportsFailedHello(t *testing.T) {
+	transport := testServer(t, func(request string) string {
+		return testResponse(request, "1000", "")
+	})
+
+	helloErr := errors.New("hello refused")
+	failures := make(chan error, 16)
+	client := NewClient(Config{
+		Username:          "user",
+		Password:          "secret",
+		Transport:         transport,
+		KeepaliveInterval: 20 * time.Millisecond,
+		OnKeepaliveError: func(err error) {
+			select {
+			case failures <- err:
+			default:
+			}
+		},
+		Interceptors: []Interceptor{func(ctx context.Context, ex *Exchange, request []byte, next Invoker) ([]byte, error) {
+			if ex.Command == "hello" {
+				return nil, helloErr
+			}
+			return next(ctx, ex, request)
+		}},
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	defer client.Close()
+
+	select {
+	case err := <-failures:
+		if !errors.Is(err, helloErr) {
+			t.Errorf("OnKeepaliveError received %v, want it to wrap %v", err, helloErr)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnKeepaliveError was not called")
+	}
+}
@@ -0,0 +1,58 @@
+package epp
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// logf writes a session event to the configured logger, if any.
+func (c *Client) logf(ctx context.Context, level slog.Level, msg string, args ...any) {
+	if c.logger != nil {
+		c.logger.Log(ctx, level, msg, args...)
+	}
+}
+
+// loggingInterceptor logs every exchange at the wire: one record per
+// command at Info (Warn for failure results, Error for transport failures)
+// and the redacted frames at Debug.
+func loggingInterceptor(logger *slog.Logger, redactor *redactor) Interceptor {
+	return func(ctx context.Context, ex *Exchange, request []byte, next Invoker) ([]byte, error) {
+		debug := logger.Enabled(ctx, slog.LevelDebug)
+		if debug && request != nil {
+			logger.DebugContext(ctx, "EPP request",
+				"command", ex.Command,
+				"clTRID", ex.ClTRID,
+				"xml", string(redactor.redact(request)))
+		}
+
+		start := time.Now()
+		response, err := next(ctx, ex, request)
+		attrs := []any{
+			"command", ex.Command,
+			"object", ex.Object,
+			"clTRID", ex.ClTRID,
+			"duration", time.Since(start),
+		}
+
+		if err != nil {
+			logger.ErrorContext(ctx, "EPP exchange failed", append(attrs, "error", err)...)
+			return response, err
+		}
+
+		if debug {
+			logger.DebugContext(ctx, "EPP response",
+				"command", ex.Command,
+				"svTRID", ex.SvTRID,
+				"xml", string(redactor.redact(response)))
+		}
+
+		level := slog.LevelInfo
+		if ex.ResultCode != "" && ClassifyResultCode(ex.ResultCode) != ResultSuccess {
+			level = slog.LevelWarn
+		}
+		logger.Log(ctx, level, "EPP command completed", append(attrs, "code", ex.ResultCode, "svTRID", ex.SvTRID)...)
+
+		return response, nil
+	}
+}
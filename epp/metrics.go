@@ -0,0 +1,317 @@
+package epp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metrics receives instrumentation events from a Client. One implementation
+// can be shared by several clients, e.g. the sessions of a Pool, so it must
+// be safe for concurrent use. MetricsCollector is the built-in
+// implementation.
+type Metrics interface {
+	ObserveCommand(observation CommandObservation)
+	ObserveReconnect(err error) // A lost session was restored (err nil) or could not be
+	ObservePollQueue(count int) // Messages waiting in the poll queue after a poll request or ack
+	SessionOpened()             // A connection was established
+	SessionClosed()             // A connection was closed
+}
+
+// CommandObservation describes one frame exchange.
+type CommandObservation struct {
+	Command       string      // EPP element, e.g. "domain:check", or "login", "hello", "greeting"
+	Type          CommandType // Command class
+	ResultCode    string      // First result code; empty for greetings and failed exchanges
+	Duration      time.Duration
+	BytesSent     int   // Request frame including the length header, zero for the greeting
+	BytesReceived int   // Response frame including the length header, zero on failure
+	Err           error // Transport or protocol failure, nil when a response was read
+}
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the command
+// latency histogram when NewMetricsCollector is given none.
+var DefaultLatencyBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// MetricsCollector aggregates Metrics events in memory and exposes them in
+// the Prometheus text exposition format, through WriteTo or as an
+// http.Handler. It is safe for concurrent use.
+type MetricsCollector struct {
+	buckets []float64
+
+	mu         sync.Mutex
+	commands   map[commandKey]int64
+	latency    map[string]*histogram
+	sent       map[string]int64
+	received   map[string]int64
+	reconnects map[string]int64
+	sessions   int64
+	pollQueue  int64
+	polled     bool
+}
+
+type commandKey struct {
+	command string
+	typ     CommandType
+	code    string
+}
+
+type histogram struct {
+	counts []int64 // Per bucket, not cumulative
+	sum    float64
+	count  int64
+}
+
+// NewMetricsCollector creates a collector whose latency histogram uses the
+// given bucket upper bounds in seconds, or DefaultLatencyBuckets if none.
+func NewMetricsCollector(buckets ...float64) *MetricsCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &MetricsCollector{
+		buckets:    buckets,
+		commands:   make(map[commandKey]int64),
+		latency:    make(map[string]*histogram),
+		sent:       make(map[string]int64),
+		received:   make(map[string]int64),
+		reconnects: make(map[string]int64),
+	}
+}
+
+func (m *MetricsCollector) ObserveCommand(o CommandObservation) {
+	code := o.ResultCode
+	if o.Err != nil {
+		code = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.commands[commandKey{command: o.Command, typ: o.Type, code: code}]++
+	m.sent[o.Command] += int64(o.BytesSent)
+	m.received[o.Command] += int64(o.BytesReceived)
+
+	h, ok := m.latency[o.Command]
+	if !ok {
+		h = &histogram{counts: make([]int64, len(m.buckets))}
+		m.latency[o.Command] = h
+	}
+	seconds := o.Duration.Seconds()
+	if i := sort.SearchFloat64s(m.buckets, seconds); i < len(m.buckets) {
+		h.counts[i]++
+	}
+	h.sum += seconds
+	h.count++
+}
+
+func (m *MetricsCollector) ObserveReconnect(err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reconnects[outcome]++
+}
+
+func (m *MetricsCollector) ObservePollQueue(count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pollQueue = int64(count)
+	m.polled = true
+}
+
+func (m *MetricsCollector) SessionOpened() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions++
+}
+
+func (m *MetricsCollector) SessionClosed() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions--
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (m *MetricsCollector) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	cw.header("epp_commands_total", "counter", "EPP frame exchanges by command, type and result code.")
+	keys := make([]commandKey, 0, len(m.commands))
+	for key := range m.commands {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.command != b.command {
+			return a.command < b.command
+		}
+		if a.typ != b.typ {
+			return a.typ < b.typ
+		}
+		return a.code < b.code
+	})
+	for _, key := range keys {
+		cw.sample("epp_commands_total", labels("command", key.command, "type", string(key.typ), "code", key.code), float64(m.commands[key]))
+	}
+
+	cw.header("epp_command_duration_seconds", "histogram", "EPP frame exchange latency by command.")
+	for _, command := range sortedKeys(m.latency) {
+		h := m.latency[command]
+		var cumulative int64
+		for i, bound := range m.buckets {
+			cumulative += h.counts[i]
+			cw.sample("epp_command_duration_seconds_bucket", labels("command", command, "le", formatFloat(bound)), float64(cumulative))
+		}
+		cw.sample("epp_command_duration_seconds_bucket", labels("command", command, "le", "+Inf"), float64(h.count))
+		cw.sample("epp_command_duration_seconds_sum", labels("command", command), h.sum)
+		cw.sample("epp_command_duration_seconds_count", labels("command", command), float64(h.count))
+	}
+
+	cw.header("epp_sent_bytes_total", "counter", "Bytes of EPP request frames by command.")
+	for _, command := range sortedKeys(m.sent) {
+		cw.sample("epp_sent_bytes_total", labels("command", command), float64(m.sent[command]))
+	}
+
+	cw.header("epp_received_bytes_total", "counter", "Bytes of EPP response frames by command.")
+	for _, command := range sortedKeys(m.received) {
+		cw.sample("epp_received_bytes_total", labels("command", command), float64(m.received[command]))
+	}
+
+	cw.header("epp_reconnects_total", "counter", "Attempts to restore a lost EPP session by outcome.")
+	for _, outcome := range sortedKeys(m.reconnects) {
+		cw.sample("epp_reconnects_total", labels("outcome", outcome), float64(m.reconnects[outcome]))
+	}
+
+	cw.header("epp_sessions", "gauge", "Open EPP connections.")
+	cw.sample("epp_sessions", "", float64(m.sessions))
+
+	if m.polled {
+		cw.header("epp_poll_queue_messages", "gauge", "Messages in the EPP poll queue as last reported by the registry.")
+		cw.sample("epp_poll_queue_messages", "", float64(m.pollQueue))
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+// ServeHTTP serves the metrics for a Prometheus scrape.
+func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) printf(format string, args ...any) {
+	if cw.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(cw.w, format, args...)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func (cw *countingWriter) header(name, typ, help string) {
+	cw.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func (cw *countingWriter) sample(name, labels string, value float64) {
+	cw.printf("%s%s %s\n", name, labels, formatFloat(value))
+}
+
+// labels formats name/value pairs as a Prometheus label set.
+func labels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metricsInterceptor reports every frame exchange to metrics.
+func metricsInterceptor(metrics Metrics) Interceptor {
+	return func(ctx context.Context, ex *Exchange, request []byte, next Invoker) ([]byte, error) {
+		start := time.Now()
+		response, err := next(ctx, ex, request)
+
+		observation := CommandObservation{
+			Command:    ex.Command,
+			Type:       ex.Type,
+			ResultCode: ex.ResultCode,
+			Duration:   time.Since(start),
+			Err:        err,
+		}
+		if request != nil {
+			observation.BytesSent = len(request) + 4
+		}
+		if err == nil {
+			observation.BytesReceived = len(response) + 4
+		}
+		metrics.ObserveCommand(observation)
+
+		return response, err
+	}
+}
+
+// observePollQueue reports the queue depth of a poll response: the msgQ
+// count, or zero when the registry answered 1300 (no messages).
+func (c *Client) observePollQueue(reply *reply) {
+	if c.metrics == nil {
+		return
+	}
+	switch {
+	case reply.msgQ != nil:
+		c.metrics.ObservePollQueue(reply.msgQ.Count)
+	case reply.code() == "1300":
+		c.metrics.ObservePollQueue(0)
+	}
+}
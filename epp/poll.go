@@ -1,6 +1,7 @@
 package epp
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 )
@@ -22,11 +23,20 @@ type Poll struct {
 }
 
 type PollResponse struct {
-	XMLName xml.Name          `xml:"epp"`
-	Result  Result            `xml:"response>result"`
-	MsgQ    *PollMessageQueue `xml:"response>msgQ,omitempty"`
-	ResData *PollResponseData `xml:"response>resData,omitempty"`
-	TrID    TrID              `xml:"response>trID"`
+	XMLName        xml.Name `xml:"epp"`
+	ResponseStatus `xml:"-"`
+	MsgQ           *PollMessageQueue `xml:"response>msgQ,omitempty"`
+	ResData        *PollResponseData `xml:"response>resData,omitempty"`
+	TrID           TrID              `xml:"response>trID"`
+}
+
+func (r *PollResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return unmarshalResponse(d, start, r)
+}
+
+func (r *PollResponse) payload() any {
+	type pollResponse PollResponse
+	return (*pollResponse)(r)
 }
 
 type PollMessageQueue struct {
@@ -42,6 +52,15 @@ type PollResponseData struct {
 }
 
 func (c *Client) PollMessage() (*PollResponse, error) {
+	return c.PollMessageContext(context.Background())
+}
+
+func (c *Client) PollMessageContext(ctx context.Context) (*PollResponse, error) {
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	pollReq := PollRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -49,7 +68,7 @@ func (c *Client) PollMessage() (*PollResponse, error) {
 			Poll: Poll{
 				Op: "req",
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -58,24 +77,35 @@ func (c *Client) PollMessage() (*PollResponse, error) {
 		return nil, fmt.Errorf("failed to marshal poll request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "poll", typ: CommandPoll, idempotent: true}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send poll request: %w", err)
 	}
 
 	var response PollResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal poll response: %w", err)
 	}
 
 	if response.Result.Code != "1000" && response.Result.Code != "1300" && response.Result.Code != "1301" {
-		return nil, fmt.Errorf("poll failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("poll", reply)
 	}
 
+	c.observePollQueue(reply)
+
 	return &response, nil
 }
 
 func (c *Client) AckPollMessage(msgID string) (*PollResponse, error) {
+	return c.AckPollMessageContext(context.Background(), msgID)
+}
+
+func (c *Client) AckPollMessageContext(ctx context.Context, msgID string) (*PollResponse, error) {
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	pollReq := PollRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -84,7 +114,7 @@ func (c *Client) AckPollMessage(msgID string) (*PollResponse, error) {
 				Op:    "ack",
 				MsgID: msgID,
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -93,20 +123,22 @@ func (c *Client) AckPollMessage(msgID string) (*PollResponse, error) {
 		return nil, fmt.Errorf("failed to marshal poll ack request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "poll", typ: CommandPoll, object: msgID}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send poll ack request: %w", err)
 	}
 
 	var response PollResponse
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal poll ack response: %w", err)
 	}
 
 	if response.Result.Code != "1000" && response.Result.Code != "1300" && response.Result.Code != "1301" {
-		return nil, fmt.Errorf("poll ack failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("poll ack", reply)
 	}
 
+	c.observePollQueue(reply)
+
 	return &response, nil
 }
 
@@ -130,33 +162,55 @@ type ChangePassword struct {
 }
 
 func (c *Client) ChangePassword(newPassword string) error {
+	return c.ChangePasswordContext(context.Background(), newPassword)
+}
+
+// ChangePasswordContext changes the account password with a login carrying
+// newPW and persists it through Config.Credentials. It holds the session
+// lock for the whole workflow so no other command can log in with the old
+// password while it is being replaced. When persisting fails, the password
+// is changed back on a new session and a *PasswordRotationError is
+// returned. Providers other than StaticCredentials must implement
+// CredentialStore, otherwise nothing is sent and ErrCredentialsReadOnly is
+// returned.
+func (c *Client) ChangePasswordContext(ctx context.Context, newPassword string) error {
+	if err := c.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	return c.rotatePassword(ctx, newPassword)
+}
+
+// changePassword logs in with credentials and newPW. On success the session
+// is authenticated and newPassword is used for later logins until it has
+// been persisted. The caller must hold c.mu.
+func (c *Client) changePassword(ctx context.Context, credentials Credentials, newPassword string) error {
+	services, err := c.loginServices()
+	if err != nil {
+		return err
+	}
+
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return err
+	}
+
 	changeReq := ChangePasswordRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
 		Command: ChangePasswordCommand{
 			Login: ChangePassword{
-				ClID:  c.username,
-				Pw:    c.password,
+				ClID:  credentials.Username,
+				Pw:    credentials.Password,
 				NewPw: newPassword,
 				Options: LoginOptions{
 					Version: "1.0",
 					Lang:    "en",
 				},
-				Svcs: LoginServices{
-					ObjURI: []string{
-						"urn:ietf:params:xml:ns:domain-1.0",
-						"urn:ietf:params:xml:ns:contact-1.0",
-					},
-					SvcExtension: &LoginServiceExtension{
-						ExtURI: []string{
-							"http://www.nic.at/xsd/at-ext-epp-1.0",
-							"http://www.nic.at/xsd/at-ext-domain-1.0",
-							"http://www.nic.at/xsd/at-ext-contact-1.0",
-						},
-					},
-				},
+				Svcs: services,
 			},
-			ClTRID: generateTransactionID(),
+			ClTRID: clTRID,
 		},
 	}
 
@@ -165,21 +219,25 @@ func (c *Client) ChangePassword(newPassword string) error {
 		return fmt.Errorf("failed to marshal change password request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	cmd := command{name: "login", typ: CommandLogin}
+	ctx, finish := c.startSpan(ctx, cmd, requestXML)
+	reply, err := c.roundTrip(ctx, cmd, requestXML)
+	finish(reply, err)
 	if err != nil {
 		return fmt.Errorf("failed to send change password request: %w", err)
 	}
 
 	var response Response
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return fmt.Errorf("failed to unmarshal change password response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return fmt.Errorf("change password failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return newEPPError("change password", reply)
 	}
 
-	c.password = newPassword
+	c.activePassword = newPassword
+	c.establishSession(services)
 
 	return nil
 }
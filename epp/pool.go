@@ -0,0 +1,462 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrPoolClosed = errors.New("EPP session pool is closed")
+
+type PoolOptions struct {
+	MaxSessions     int           // Upper bound of concurrent sessions, e.g. the registrar's nic.at session limit
+	HealthCheckIdle time.Duration // Sessions idle longer than this are checked with hello before reuse
+	MinBackoff      time.Duration // First delay after 2502 session limit exceeded
+	MaxBackoff      time.Duration // Upper bound for the exponential session limit backoff
+}
+
+// Pool maintains up to MaxSessions logged-in sessions and runs each command
+// on a session of its own, so independent commands execute in parallel. It
+// exposes the same command methods as Client. Session-level operations
+// (Login, Logout, ChangePassword) are handled by the pool itself.
+type Pool struct {
+	config  Config
+	options PoolOptions
+	slots   chan struct{}
+
+	mu           sync.Mutex
+	idle         []*Client
+	closed       bool
+	backoff      time.Duration
+	backoffUntil time.Time
+}
+
+func NewPool(config Config, options PoolOptions) *Pool {
+	if options.MaxSessions <= 0 {
+		options.MaxSessions = 1
+	}
+	if options.HealthCheckIdle == 0 {
+		options.HealthCheckIdle = time.Minute
+	}
+	if options.MinBackoff == 0 {
+		options.MinBackoff = time.Second
+	}
+	if options.MaxBackoff == 0 {
+		options.MaxBackoff = time.Minute
+	}
+	if config.TrIDGenerator == nil {
+		// One generator keeps IDs ordered across all sessions.
+		config.TrIDGenerator = ULIDTrIDGenerator()
+	}
+
+	return &Pool{
+		config:  config,
+		options: options,
+		slots:   make(chan struct{}, options.MaxSessions),
+	}
+}
+
+// Close logs out and closes all idle sessions. Sessions in use are closed
+// when their command completes.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	var errs []error
+	for _, c := range idle {
+		if err := c.Logout(); err != nil {
+			errs = append(errs, err)
+		}
+		c.Close()
+	}
+
+	return errors.Join(errs...)
+}
+
+func poolDo[T any](ctx context.Context, p *Pool, fn func(*Client) (T, error)) (T, error) {
+	var zero T
+
+	c, err := p.acquire(ctx)
+	if err != nil {
+		return zero, err
+	}
+	defer p.release(c)
+
+	return fn(c)
+}
+
+func (p *Pool) acquire(ctx context.Context) (*Client, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	c, err := p.session(ctx)
+	if err != nil {
+		<-p.slots
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (p *Pool) release(c *Client) {
+	defer func() { <-p.slots }()
+
+	if c.usable() {
+		p.mu.Lock()
+		if !p.closed {
+			p.idle = append(p.idle, c)
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+	}
+
+	c.Close()
+}
+
+// session returns a healthy idle session or dials a new one. The caller
+// must hold a slot.
+func (p *Pool) session(ctx context.Context) (*Client, error) {
+	for {
+		c, err := p.popIdle()
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return p.dial(ctx)
+		}
+		if p.healthy(ctx, c) {
+			return c, nil
+		}
+		c.Close()
+	}
+}
+
+func (p *Pool) popIdle() (*Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	if len(p.idle) == 0 {
+		return nil, nil
+	}
+
+	c := p.idle[len(p.idle)-1]
+	p.idle = p.idle[:len(p.idle)-1]
+	return c, nil
+}
+
+func (p *Pool) healthy(ctx context.Context, c *Client) bool {
+	if !c.usable() {
+		return false
+	}
+	if time.Since(c.idleSince()) < p.options.HealthCheckIdle {
+		return true
+	}
+	_, err := c.HelloContext(ctx)
+	return err == nil
+}
+
+// dial opens and logs in a new session, backing off while the registry
+// reports that the account's session limit is exhausted.
+func (p *Pool) dial(ctx context.Context) (*Client, error) {
+	ctx = withoutClTRID(ctx)
+	for {
+		if err := p.waitBackoff(ctx); err != nil {
+			return nil, err
+		}
+
+		c := NewClient(p.config)
+		err := c.ConnectContext(ctx)
+		if err == nil {
+			err = c.LoginContext(ctx)
+		}
+		if err == nil {
+			p.resetBackoff()
+			return c, nil
+		}
+		c.Close()
+
+		if !isSessionLimitExceeded(err) {
+			return nil, fmt.Errorf("failed to open pooled EPP session: %w", err)
+		}
+		p.increaseBackoff()
+	}
+}
+
+func isSessionLimitExceeded(err error) bool {
+	return errors.Is(err, ErrSessionLimitExceeded)
+}
+
+func (p *Pool) waitBackoff(ctx context.Context) error {
+	p.mu.Lock()
+	wait := time.Until(p.backoffUntil)
+	p.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) increaseBackoff() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.backoff == 0 {
+		p.backoff = p.options.MinBackoff
+	} else {
+		p.backoff *= 2
+	}
+	if p.backoff > p.options.MaxBackoff {
+		p.backoff = p.options.MaxBackoff
+	}
+	p.backoffUntil = time.Now().Add(p.backoff)
+}
+
+func (p *Pool) resetBackoff() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.backoff = 0
+	p.backoffUntil = time.Time{}
+}
+
+func (p *Pool) Hello() (*HelloResponse, error) {
+	return p.HelloContext(context.Background())
+}
+
+func (p *Pool) HelloContext(ctx context.Context) (*HelloResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*HelloResponse, error) {
+		return c.HelloContext(ctx)
+	})
+}
+
+func (p *Pool) CheckDomain(domains []string) (*CheckDomainResponse, error) {
+	return p.CheckDomainContext(context.Background(), domains)
+}
+
+func (p *Pool) CheckDomainContext(ctx context.Context, domains []string) (*CheckDomainResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*CheckDomainResponse, error) {
+		return c.CheckDomainContext(ctx, domains)
+	})
+}
+
+func (p *Pool) CreateDomain(domain Domain) (*CreateDomainResponse, error) {
+	return p.CreateDomainContext(context.Background(), domain)
+}
+
+func (p *Pool) CreateDomainContext(ctx context.Context, domain Domain) (*CreateDomainResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*CreateDomainResponse, error) {
+		return c.CreateDomainContext(ctx, domain)
+	})
+}
+
+func (p *Pool) CreateDomainWithDNSSEC(domain Domain, dsRecords []DNSSECData) (*CreateDomainResponse, error) {
+	return p.CreateDomainWithDNSSECContext(context.Background(), domain, dsRecords)
+}
+
+func (p *Pool) CreateDomainWithDNSSECContext(ctx context.Context, domain Domain, dsRecords []DNSSECData) (*CreateDomainResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*CreateDomainResponse, error) {
+		return c.CreateDomainWithDNSSECContext(ctx, domain, dsRecords)
+	})
+}
+
+func (p *Pool) InfoDomain(domainName string) (*InfoDomainResponse, error) {
+	return p.InfoDomainContext(context.Background(), domainName)
+}
+
+func (p *Pool) InfoDomainContext(ctx context.Context, domainName string) (*InfoDomainResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*InfoDomainResponse, error) {
+		return c.InfoDomainContext(ctx, domainName)
+	})
+}
+
+func (p *Pool) UpdateDomain(domainName string, add *DomainUpdateAdd, rem *DomainUpdateRem, chg *DomainUpdateChg) (*Response, error) {
+	return p.UpdateDomainContext(context.Background(), domainName, add, rem, chg)
+}
+
+func (p *Pool) UpdateDomainContext(ctx context.Context, domainName string, add *DomainUpdateAdd, rem *DomainUpdateRem, chg *DomainUpdateChg) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.UpdateDomainContext(ctx, domainName, add, rem, chg)
+	})
+}
+
+func (p *Pool) UpdateDomainNameservers(domainName string, add, remove []UpdateDomainHostAttr) (*Response, error) {
+	return p.UpdateDomainNameserversContext(context.Background(), domainName, add, remove)
+}
+
+func (p *Pool) UpdateDomainNameserversContext(ctx context.Context, domainName string, add, remove []UpdateDomainHostAttr) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.UpdateDomainNameserversContext(ctx, domainName, add, remove)
+	})
+}
+
+func (p *Pool) UpdateDomainDNSSEC(domainName string, add, rem, chg []DNSSECData) (*Response, error) {
+	return p.UpdateDomainDNSSECContext(context.Background(), domainName, add, rem, chg)
+}
+
+func (p *Pool) UpdateDomainDNSSECContext(ctx context.Context, domainName string, add, rem, chg []DNSSECData) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.UpdateDomainDNSSECContext(ctx, domainName, add, rem, chg)
+	})
+}
+
+func (p *Pool) DeleteDomain(domainName string) (*Response, error) {
+	return p.DeleteDomainContext(context.Background(), domainName)
+}
+
+func (p *Pool) DeleteDomainContext(ctx context.Context, domainName string) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.DeleteDomainContext(ctx, domainName)
+	})
+}
+
+func (p *Pool) DeleteDomainWithSchedule(domainName, scheduleDate string) (*Response, error) {
+	return p.DeleteDomainWithScheduleContext(context.Background(), domainName, scheduleDate)
+}
+
+func (p *Pool) DeleteDomainWithScheduleContext(ctx context.Context, domainName, scheduleDate string) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.DeleteDomainWithScheduleContext(ctx, domainName, scheduleDate)
+	})
+}
+
+func (p *Pool) TransferRequestDomain(domainName, authInfo string) (*TransferDomainResponse, error) {
+	return p.TransferRequestDomainContext(context.Background(), domainName, authInfo)
+}
+
+func (p *Pool) TransferRequestDomainContext(ctx context.Context, domainName, authInfo string) (*TransferDomainResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*TransferDomainResponse, error) {
+		return c.TransferRequestDomainContext(ctx, domainName, authInfo)
+	})
+}
+
+func (p *Pool) TransferQueryDomain(domainName string) (*TransferDomainResponse, error) {
+	return p.TransferQueryDomainContext(context.Background(), domainName)
+}
+
+func (p *Pool) TransferQueryDomainContext(ctx context.Context, domainName string) (*TransferDomainResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*TransferDomainResponse, error) {
+		return c.TransferQueryDomainContext(ctx, domainName)
+	})
+}
+
+func (p *Pool) TransferCancelDomain(domainName string) (*TransferDomainResponse, error) {
+	return p.TransferCancelDomainContext(context.Background(), domainName)
+}
+
+func (p *Pool) TransferCancelDomainContext(ctx context.Context, domainName string) (*TransferDomainResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*TransferDomainResponse, error) {
+		return c.TransferCancelDomainContext(ctx, domainName)
+	})
+}
+
+func (p *Pool) WithdrawDomain(domainName string) (*Response, error) {
+	return p.WithdrawDomainContext(context.Background(), domainName)
+}
+
+func (p *Pool) WithdrawDomainContext(ctx context.Context, domainName string) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.WithdrawDomainContext(ctx, domainName)
+	})
+}
+
+func (p *Pool) WithdrawDomainProper(domainName string) (*WithdrawResponse, error) {
+	return p.WithdrawDomainProperContext(context.Background(), domainName)
+}
+
+func (p *Pool) WithdrawDomainProperContext(ctx context.Context, domainName string) (*WithdrawResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*WithdrawResponse, error) {
+		return c.WithdrawDomainProperContext(ctx, domainName)
+	})
+}
+
+func (p *Pool) WithdrawDomainWithZoneDelete(domainName string, zoneDelete bool) (*Response, error) {
+	return p.WithdrawDomainWithZoneDeleteContext(context.Background(), domainName, zoneDelete)
+}
+
+func (p *Pool) WithdrawDomainWithZoneDeleteContext(ctx context.Context, domainName string, zoneDelete bool) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.WithdrawDomainWithZoneDeleteContext(ctx, domainName, zoneDelete)
+	})
+}
+
+func (p *Pool) CreateContact(contact *Contact) (*CreateContactResponse, error) {
+	return p.CreateContactContext(context.Background(), contact)
+}
+
+func (p *Pool) CreateContactContext(ctx context.Context, contact *Contact) (*CreateContactResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*CreateContactResponse, error) {
+		return c.CreateContactContext(ctx, contact)
+	})
+}
+
+func (p *Pool) InfoContact(contactID string) (*InfoContactResponse, error) {
+	return p.InfoContactContext(context.Background(), contactID)
+}
+
+func (p *Pool) InfoContactContext(ctx context.Context, contactID string) (*InfoContactResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*InfoContactResponse, error) {
+		return c.InfoContactContext(ctx, contactID)
+	})
+}
+
+func (p *Pool) UpdateContact(contactID string, add *ContactUpdateAdd, rem *ContactUpdateRem, chg *ContactUpdateChg) (*Response, error) {
+	return p.UpdateContactContext(context.Background(), contactID, add, rem, chg)
+}
+
+func (p *Pool) UpdateContactContext(ctx context.Context, contactID string, add *ContactUpdateAdd, rem *ContactUpdateRem, chg *ContactUpdateChg) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.UpdateContactContext(ctx, contactID, add, rem, chg)
+	})
+}
+
+func (p *Pool) DeleteContact(contactID string) (*Response, error) {
+	return p.DeleteContactContext(context.Background(), contactID)
+}
+
+func (p *Pool) DeleteContactContext(ctx context.Context, contactID string) (*Response, error) {
+	return poolDo(ctx, p, func(c *Client) (*Response, error) {
+		return c.DeleteContactContext(ctx, contactID)
+	})
+}
+
+func (p *Pool) PollMessage() (*PollResponse, error) {
+	return p.PollMessageContext(context.Background())
+}
+
+func (p *Pool) PollMessageContext(ctx context.Context) (*PollResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*PollResponse, error) {
+		return c.PollMessageContext(ctx)
+	})
+}
+
+func (p *Pool) AckPollMessage(msgID string) (*PollResponse, error) {
+	return p.AckPollMessageContext(context.Background(), msgID)
+}
+
+func (p *Pool) AckPollMessageContext(ctx context.Context, msgID string) (*PollResponse, error) {
+	return poolDo(ctx, p, func(c *Client) (*PollResponse, error) {
+		return c.AckPollMessageContext(ctx, msgID)
+	})
+}
@@ -0,0 +1,92 @@
+package epp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	DefaultMaxFrameSize = 4 << 20 // 4 MiB, far above any nic.at response
+	DefaultMaxXMLDepth  = 64
+	DefaultMaxXMLTokens = 250000
+)
+
+var (
+	ErrInvalidFrame    = errors.New("invalid EPP frame")
+	ErrFrameTooLarge   = errors.New("EPP frame exceeds maximum size")
+	ErrXMLTooDeep      = errors.New("EPP response exceeds maximum XML depth")
+	ErrXMLTooManyNodes = errors.New("EPP response exceeds maximum XML token count")
+)
+
+// ProtocolError is returned when the server sends a frame that violates the
+// transport framing or the configured decoding limits. The connection is
+// closed because the peer can no longer be trusted to be in sync; a
+// resilient client restores the session on the next command.
+type ProtocolError struct {
+	Command string
+	Detail  string
+	Err     error // ErrInvalidFrame, ErrFrameTooLarge, ErrXMLTooDeep or ErrXMLTooManyNodes
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("EPP %s protocol error: %v: %s", e.Command, e.Err, e.Detail)
+}
+
+func (e *ProtocolError) Unwrap() error {
+	return e.Err
+}
+
+// frameLimits bounds what the client accepts from the server.
+type frameLimits struct {
+	maxFrameSize int
+	maxXMLDepth  int
+	maxXMLTokens int
+}
+
+// checkLength validates the total frame length announced by a frame header,
+// before anything is allocated for the body.
+func (l frameLimits) checkLength(length uint32) error {
+	if length < 4 {
+		return &ProtocolError{Detail: fmt.Sprintf("length header %d is shorter than the header itself", length), Err: ErrInvalidFrame}
+	}
+	if l.maxFrameSize > 0 && uint64(length) > uint64(l.maxFrameSize) {
+		return &ProtocolError{Detail: fmt.Sprintf("%d bytes announced, limit is %d", length, l.maxFrameSize), Err: ErrFrameTooLarge}
+	}
+	return nil
+}
+
+// checkDocument scans a response once without building any values, so the
+// decoders that run afterwards only ever see documents within the limits.
+func (l frameLimits) checkDocument(document []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	depth, tokens := 0, 0
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// Malformed XML is reported by the command's own decoder.
+			return nil
+		}
+
+		tokens++
+		if l.maxXMLTokens > 0 && tokens > l.maxXMLTokens {
+			return &ProtocolError{Detail: fmt.Sprintf("more than %d tokens", l.maxXMLTokens), Err: ErrXMLTooManyNodes}
+		}
+
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+			if l.maxXMLDepth > 0 && depth > l.maxXMLDepth {
+				return &ProtocolError{Detail: fmt.Sprintf("elements nested deeper than %d", l.maxXMLDepth), Err: ErrXMLTooDeep}
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
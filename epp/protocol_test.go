@@ -0,0 +1,64 @@
+package epp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestClientRejectsFramesBeyondLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{
+			name: "oversized frame",
+			body: `<extValue><reason>` + strings.Repeat("x", 2048) + `</reason></extValue>`,
+			want: ErrFrameTooLarge,
+		},
+		{
+			name: "over-deep XML",
+			body: strings.Repeat("<x>", 16) + strings.Repeat("</x>", 16),
+			want: ErrXMLTooDeep,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := testServer(t, func(request string) string {
+				if strings.Contains(request, "<domain:check") {
+					return testResponse(request, "1000", tt.body)
+				}
+				return testResponse(request, "1000", "")
+			})
+
+			client := NewClient(Config{
+				Username:     "user",
+				Password:     "secret",
+				Transport:    transport,
+				MaxFrameSize: 1024,
+				MaxXMLDepth:  8,
+			})
+			if err := client.Connect(); err != nil {
+				t.Fatalf("Connect: %v", err)
+			}
+			if err := client.Login(); err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+			defer client.Close()
+
+			_, err := client.CheckDomain([]string{"example.at"})
+			var protocolErr *ProtocolError
+			if !errors.As(err, &protocolErr) || !errors.Is(err, tt.want) {
+				t.Fatalf("CheckDomain returned %v, want a *ProtocolError wrapping %v", err, tt.want)
+			}
+			if protocolErr.Command != "domain:check" {
+				t.Errorf("ProtocolError.Command = %q, want domain:check", protocolErr.Command)
+			}
+			if state := client.State(); state != StateDisconnected {
+				t.Errorf("State after a rejected frame = %s, want %s", state, StateDisconnected)
+			}
+		})
+	}
+}
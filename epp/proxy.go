@@ -0,0 +1,276 @@
+package epp
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ProxyDialer returns a Dialer that tunnels connections through the proxy
+// at proxyURL. Supported schemes are socks5 and socks5h (RFC 1928, with
+// optional username/password authentication) and http (CONNECT, with
+// optional basic authentication). The proxy itself is reached through
+// forward, or a plain net.Dialer when forward is nil. The target host name
+// is always resolved by the proxy.
+func ProxyDialer(proxyURL string, forward Dialer) (Dialer, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", proxyURL)
+	}
+	if forward == nil {
+		forward = &net.Dialer{}
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h":
+		return &socks5Dialer{proxy: hostPort(u, "1080"), user: u.User, forward: forward}, nil
+	case "http":
+		return &connectDialer{proxy: hostPort(u, "80"), user: u.User, forward: forward}, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
+
+type socks5Dialer struct {
+	proxy   string
+	user    *url.Userinfo
+	forward Dialer
+}
+
+var socks5Replies = map[byte]string{
+	1: "general SOCKS server failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+func (d *socks5Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, "tcp", d.proxy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SOCKS5 proxy: %w", err)
+	}
+
+	stop, ctxDeadline := watchConn(ctx, conn, 0)
+	err = d.handshake(conn, address)
+	stop()
+	if err != nil {
+		conn.Close()
+		if ctxErr := contextError(ctx, ctxDeadline, err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func (d *socks5Dialer) handshake(conn net.Conn, address string) error {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid SOCKS5 target address: %w", err)
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid SOCKS5 target port: %w", err)
+	}
+
+	methods := []byte{0x00}
+	if d.user != nil {
+		methods = []byte{0x00, 0x02}
+	}
+	if _, err := conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return fmt.Errorf("failed to send SOCKS5 greeting: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("failed to read SOCKS5 greeting: %w", err)
+	}
+	if reply[0] != 0x05 {
+		return fmt.Errorf("unexpected SOCKS version %d", reply[0])
+	}
+
+	switch reply[1] {
+	case 0x00:
+	case 0x02:
+		if d.user == nil {
+			return errors.New("SOCKS5 proxy requires authentication")
+		}
+		if err := d.authenticate(conn); err != nil {
+			return err
+		}
+	default:
+		return errors.New("SOCKS5 proxy accepted none of the offered authentication methods")
+	}
+
+	request := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("SOCKS5 target host name too long: %s", host)
+		}
+		request = append(request, 0x03, byte(len(host)))
+		request = append(request, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		request = append(request, 0x01)
+		request = append(request, ip4...)
+	} else {
+		request = append(request, 0x04)
+		request = append(request, ip.To16()...)
+	}
+	request = binary.BigEndian.AppendUint16(request, uint16(port))
+
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("failed to send SOCKS5 connect request: %w", err)
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("failed to read SOCKS5 connect reply: %w", err)
+	}
+	if header[1] != 0x00 {
+		message, ok := socks5Replies[header[1]]
+		if !ok {
+			message = fmt.Sprintf("reply code %d", header[1])
+		}
+		return fmt.Errorf("SOCKS5 proxy refused connection to %s: %s", address, message)
+	}
+
+	var boundLength int
+	switch header[3] {
+	case 0x01:
+		boundLength = net.IPv4len
+	case 0x04:
+		boundLength = net.IPv6len
+	case 0x03:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fmt.Errorf("failed to read SOCKS5 bound address: %w", err)
+		}
+		boundLength = int(length[0])
+	default:
+		return fmt.Errorf("unknown SOCKS5 address type %d", header[3])
+	}
+	if _, err := io.ReadFull(conn, make([]byte, boundLength+2)); err != nil {
+		return fmt.Errorf("failed to read SOCKS5 bound address: %w", err)
+	}
+
+	return nil
+}
+
+// authenticate performs the RFC 1929 username/password sub-negotiation.
+func (d *socks5Dialer) authenticate(conn net.Conn) error {
+	username := d.user.Username()
+	password, _ := d.user.Password()
+	if len(username) > 255 || len(password) > 255 {
+		return errors.New("SOCKS5 credentials too long")
+	}
+
+	request := []byte{0x01, byte(len(username))}
+	request = append(request, username...)
+	request = append(request, byte(len(password)))
+	request = append(request, password...)
+	if _, err := conn.Write(request); err != nil {
+		return fmt.Errorf("failed to send SOCKS5 credentials: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("failed to read SOCKS5 authentication reply: %w", err)
+	}
+	if reply[1] != 0x00 {
+		return errors.New("SOCKS5 proxy rejected the credentials")
+	}
+
+	return nil
+}
+
+type connectDialer struct {
+	proxy   string
+	user    *url.Userinfo
+	forward Dialer
+}
+
+func (d *connectDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, "tcp", d.proxy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to HTTP proxy: %w", err)
+	}
+
+	stop, ctxDeadline := watchConn(ctx, conn, 0)
+	tunnel, err := d.handshake(conn, address)
+	stop()
+	if err != nil {
+		conn.Close()
+		if ctxErr := contextError(ctx, ctxDeadline, err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+
+	return tunnel, nil
+}
+
+func (d *connectDialer) handshake(conn net.Conn, address string) (net.Conn, error) {
+	request := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: address},
+		Host:   address,
+		Header: make(http.Header),
+	}
+	if d.user != nil {
+		password, _ := d.user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.user.Username() + ":" + password))
+		request.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := request.Write(conn); err != nil {
+		return nil, fmt.Errorf("failed to send HTTP CONNECT request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HTTP CONNECT response: %w", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP proxy refused connection to %s: %s", address, response.Status)
+	}
+
+	if reader.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, reader: reader}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn keeps bytes the proxy sent right after its CONNECT response.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
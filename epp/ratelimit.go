@@ -0,0 +1,149 @@
+package epp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token bucket: Rate commands per second are admitted on
+// average, with bursts of up to Burst commands.
+type RateLimit struct {
+	Rate  float64 // Sustained commands per second
+	Burst int     // Commands admitted at once after an idle period, defaults to 1
+}
+
+// RateLimitStats reports how long commands of one type waited for the
+// limiter.
+type RateLimitStats struct {
+	Commands  int64         // Commands admitted
+	Delayed   int64         // Commands that had to wait for a token
+	TotalWait time.Duration // Sum of all waits
+	MaxWait   time.Duration // Longest single wait
+}
+
+// RateLimiter throttles commands per CommandType before they are sent. One
+// limiter can be shared by several clients, which is how a Pool applies an
+// account-wide quota across all of its sessions. Login and logout are never
+// throttled, so sessions can always be opened and closed. It is safe for
+// concurrent use.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[CommandType]*bucket
+	stats   map[CommandType]*RateLimitStats
+}
+
+type bucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter for the given command types. Types
+// without an entry or with a non-positive Rate are never delayed.
+func NewRateLimiter(limits map[CommandType]RateLimit) *RateLimiter {
+	l := &RateLimiter{
+		buckets: make(map[CommandType]*bucket, len(limits)),
+		stats:   make(map[CommandType]*RateLimitStats),
+	}
+	now := time.Now()
+	for typ, limit := range limits {
+		if limit.Rate <= 0 {
+			continue
+		}
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+		l.buckets[typ] = &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+	}
+	return l
+}
+
+// Wait blocks until a command of the given type may be sent or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, typ CommandType) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve(typ)
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			l.cancel(typ)
+			return fmt.Errorf("EPP %s rate limit wait aborted: %w", typ, ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	l.record(typ, delay)
+	return nil
+}
+
+// reserve takes a token, possibly from the future, and returns how long the
+// caller has to wait until that token exists.
+func (l *RateLimiter) reserve(typ CommandType) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[typ]
+	if !ok {
+		return 0
+	}
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.limit.Rate
+	if burst := float64(b.limit.Burst); b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.limit.Rate * float64(time.Second))
+}
+
+// cancel returns the token of an abandoned reservation.
+func (l *RateLimiter) cancel(typ CommandType) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[typ]; ok {
+		b.tokens++
+	}
+}
+
+func (l *RateLimiter) record(typ CommandType, delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.stats[typ]
+	if !ok {
+		s = &RateLimitStats{}
+		l.stats[typ] = s
+	}
+	s.Commands++
+	if delay > 0 {
+		s.Delayed++
+		s.TotalWait += delay
+		if delay > s.MaxWait {
+			s.MaxWait = delay
+		}
+	}
+}
+
+// Stats returns a snapshot of the wait-time counters per command type.
+func (l *RateLimiter) Stats() map[CommandType]RateLimitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[CommandType]RateLimitStats, len(l.stats))
+	for typ, s := range l.stats {
+		stats[typ] = *s
+	}
+	return stats
+}
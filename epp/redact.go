@@ -0,0 +1,117 @@
+package epp
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// redactedPlaceholder replaces the content of masked elements.
+const redactedPlaceholder = "[REDACTED]"
+
+// secretElements are masked in every logged or journaled frame, whatever
+// their namespace prefix.
+var secretElements = []string{"pw", "newPW", "authInfo"}
+
+// DefaultRedactedContactFields are the contact elements masked when
+// RedactionPolicy.ContactFields is nil: everything that identifies a
+// registrant as a natural person.
+var DefaultRedactedContactFields = []string{"name", "org", "street", "city", "sp", "pc", "voice", "fax", "email"}
+
+// RedactionPolicy selects the personal data masked before frames are logged
+// or journaled. Passwords (pw, newPW) and authInfo are always masked.
+type RedactionPolicy struct {
+	// ContactFields lists element names of the contact namespace whose
+	// content is masked, under whatever prefix the frame binds to that
+	// namespace, or none where it is the default namespace. Nil selects
+	// DefaultRedactedContactFields; an empty slice masks no contact data.
+	ContactFields []string
+}
+
+// contactNamespaceDecl finds the prefixes a frame binds to the contact
+// namespace; an empty prefix is a default namespace declaration.
+var contactNamespaceDecl = regexp.MustCompile(`xmlns(?::([\w.-]+))?\s*=\s*["']` + regexp.QuoteMeta(NamespaceContact) + `["']`)
+
+// redactor masks elements by qualified name without re-encoding the frame,
+// so everything else stays byte-for-byte as it was on the wire. Secret
+// elements are masked under any prefix, contact fields only in the contact
+// namespace, so that e.g. domain:name stays readable.
+type redactor struct {
+	open    *regexp.Regexp
+	secrets map[string]bool
+}
+
+func (p RedactionPolicy) redactor() *redactor {
+	fields := p.ContactFields
+	if fields == nil {
+		fields = DefaultRedactedContactFields
+	}
+
+	secrets := make(map[string]bool, len(secretElements))
+	names := make([]string, 0, len(secretElements)+len(fields))
+	for _, name := range secretElements {
+		secrets[name] = true
+		names = append(names, regexp.QuoteMeta(name))
+	}
+	for _, name := range fields {
+		names = append(names, regexp.QuoteMeta(name))
+	}
+
+	return &redactor{
+		open:    regexp.MustCompile(`<((?:([\w.-]+):)?(` + strings.Join(names, "|") + `))(?:\s[^>]*[^/>])?\s*>`),
+		secrets: secrets,
+	}
+}
+
+// Redact returns a copy of frame with the content of every sensitive
+// element replaced by a placeholder.
+func (p RedactionPolicy) Redact(frame []byte) []byte {
+	return p.redactor().redact(frame)
+}
+
+func (r *redactor) redact(frame []byte) []byte {
+	contactPrefixes := map[string]bool{"contact": true}
+	for _, decl := range contactNamespaceDecl.FindAllSubmatch(frame, -1) {
+		contactPrefixes[string(decl[1])] = true
+	}
+
+	var out bytes.Buffer
+	rest := frame
+
+	for {
+		match := r.open.FindSubmatchIndex(rest)
+		if match == nil {
+			break
+		}
+
+		var prefix string
+		if match[4] >= 0 {
+			prefix = string(rest[match[4]:match[5]])
+		}
+		if !r.secrets[string(rest[match[6]:match[7]])] && !contactPrefixes[prefix] {
+			// A contact field name in another namespace.
+			out.Write(rest[:match[1]])
+			rest = rest[match[1]:]
+			continue
+		}
+
+		name := rest[match[2]:match[3]]
+		contentStart := match[1]
+		closing := append(append([]byte("</"), name...), '>')
+		end := bytes.Index(rest[contentStart:], closing)
+		if end < 0 {
+			// Unterminated element: mask everything after it.
+			out.Write(rest[:contentStart])
+			out.WriteString(redactedPlaceholder)
+			return out.Bytes()
+		}
+
+		out.Write(rest[:contentStart])
+		out.WriteString(redactedPlaceholder)
+		out.Write(closing)
+		rest = rest[contentStart+end+len(closing):]
+	}
+
+	out.Write(rest)
+	return out.Bytes()
+}
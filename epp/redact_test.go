@@ -0,0 +1,52 @@
+package epp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactionPolicyRedact(t *testing.T) {
+	frame := `<epp xmlns="urn:ietf:params:xml:ns:epp-1.0"><command>` +
+		`<login><clID>registrar</clID><pw>old-secret</pw><newPW>new-secret</newPW></login>` +
+		`<create><contact:create xmlns:contact="urn:ietf:params:xml:ns:contact-1.0">` +
+		`<contact:postalInfo type="int"><contact:name>Prefixed Person</contact:name></contact:postalInfo>` +
+		`<contact:email>prefixed@example.at</contact:email>` +
+		`<contact:authInfo><contact:pw>contact-secret</contact:pw></contact:authInfo></contact:create></create>` +
+		`<info><infData xmlns="urn:ietf:params:xml:ns:contact-1.0"><name>Default Person</name><email>default@example.at</email></infData></info>` +
+		`<check><c:chkData xmlns:c="urn:ietf:params:xml:ns:contact-1.0"><c:name>Other Person</c:name></c:chkData></check>` +
+		`<update><domain:update xmlns:domain="urn:ietf:params:xml:ns:domain-1.0"><domain:name>example.at</domain:name>` +
+		`<domain:authInfo><domain:pw>domain-secret</domain:pw></domain:authInfo></domain:update></update>` +
+		`</command></epp>`
+
+	redacted := string(RedactionPolicy{}.Redact([]byte(frame)))
+
+	for _, secret := range []string{
+		"old-secret", "new-secret", "contact-secret", "domain-secret",
+		"Prefixed Person", "prefixed@example.at",
+		"Default Person", "default@example.at",
+		"Other Person",
+	} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("redacted frame still contains %q: %s", secret, redacted)
+		}
+	}
+	for _, kept := range []string{"<clID>registrar</clID>", "<domain:name>example.at</domain:name>", "<contact:name>" + redactedPlaceholder + "</contact:name>"} {
+		if !strings.Contains(redacted, kept) {
+			t.Errorf("redacted frame lacks %q: %s", kept, redacted)
+		}
+	}
+}
+
+func TestRedactionPolicyWithoutContactFields(t *testing.T) {
+	frame := `<contact:create xmlns:contact="urn:ietf:params:xml:ns:contact-1.0"><contact:name>Jane Doe</contact:name>` +
+		`<contact:authInfo><contact:pw>secret</contact:pw></contact:authInfo></contact:create>`
+
+	redacted := string(RedactionPolicy{ContactFields: []string{}}.Redact([]byte(frame)))
+
+	if !strings.Contains(redacted, "Jane Doe") {
+		t.Errorf("contact name masked although no contact fields are selected: %s", redacted)
+	}
+	if strings.Contains(redacted, "secret") {
+		t.Errorf("authInfo not masked: %s", redacted)
+	}
+}
@@ -0,0 +1,117 @@
+package epp
+
+import (
+	"errors"
+
+	ierr "github.com/ParadoxTR/epp-at-go/internal/errors"
+)
+
+// ResultClass tells callers how to react to a result code.
+type ResultClass int
+
+const (
+	ResultSuccess        ResultClass = iota // 1xxx
+	ResultClientError                       // 20xx, 21xx, 2307: the request is malformed or unsupported; fix it
+	ResultAuthorization                     // 22xx: credentials or authInfo rejected
+	ResultPolicy                            // 23xx, 2104-2106: object state or registry policy forbids the command
+	ResultRetryable                         // 24xx: transient server failure, the command may be repeated
+	ResultSessionClosing                    // 25xx: the server closes the session after this response
+	ResultUnknown
+)
+
+func (class ResultClass) String() string {
+	switch class {
+	case ResultSuccess:
+		return "success"
+	case ResultClientError:
+		return "client error"
+	case ResultAuthorization:
+		return "authorization error"
+	case ResultPolicy:
+		return "policy error"
+	case ResultRetryable:
+		return "retryable error"
+	case ResultSessionClosing:
+		return "session closing"
+	default:
+		return "unknown"
+	}
+}
+
+func ClassifyResultCode(code string) ResultClass {
+	switch code {
+	case ierr.CodeBillingFailure, ierr.CodeObjectNotEligibleForRenewal, ierr.CodeObjectNotEligibleForTransfer:
+		return ResultPolicy
+	case ierr.CodeUnimplementedObjectService:
+		return ResultClientError
+	}
+
+	if len(code) != 4 {
+		return ResultUnknown
+	}
+	switch code[:2] {
+	case "10", "13", "15":
+		return ResultSuccess
+	case "20", "21":
+		return ResultClientError
+	case "22":
+		return ResultAuthorization
+	case "23":
+		return ResultPolicy
+	case "24":
+		return ResultRetryable
+	case "25":
+		return ResultSessionClosing
+	default:
+		return ResultUnknown
+	}
+}
+
+func (e *EPPError) Class() ResultClass {
+	return ClassifyResultCode(e.Code)
+}
+
+// Retryable reports whether repeating the command may succeed: transient
+// failures (2400), and 2500/2502 once a new session is established. 2501
+// is excluded because logging in again with the same credentials fails.
+func (e *EPPError) Retryable() bool {
+	switch e.Class() {
+	case ResultRetryable:
+		return true
+	case ResultSessionClosing:
+		return e.Code != ierr.CodeAuthenticationErrorServerClosing
+	default:
+		return false
+	}
+}
+
+// IsRetryable reports whether err is an *EPPError worth retrying. Transport
+// failures are not covered: whether the registry executed the command is
+// unknown, so only idempotent commands should be repeated after them.
+func IsRetryable(err error) bool {
+	var eppErr *EPPError
+	return errors.As(err, &eppErr) && eppErr.Retryable()
+}
+
+func IsClientError(err error) bool {
+	return hasResultClass(err, ResultClientError)
+}
+
+func IsAuthorizationError(err error) bool {
+	return hasResultClass(err, ResultAuthorization)
+}
+
+func IsPolicyError(err error) bool {
+	return hasResultClass(err, ResultPolicy)
+}
+
+// IsSessionClosing reports whether the server ended the session with err.
+// The client has already dropped the connection in that case.
+func IsSessionClosing(err error) bool {
+	return hasResultClass(err, ResultSessionClosing)
+}
+
+func hasResultClass(err error, class ResultClass) bool {
+	var eppErr *EPPError
+	return errors.As(err, &eppErr) && eppErr.Class() == class
+}
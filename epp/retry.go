@@ -0,0 +1,163 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	ierr "github.com/ParadoxTR/epp-at-go/internal/errors"
+)
+
+// ErrReconciled is returned when a failed non-idempotent command is not
+// repeated because the RetryPolicy's Reconcile function found that an
+// earlier attempt already took effect at the registry.
+var ErrReconciled = errors.New("EPP command already applied by an earlier attempt")
+
+// DefaultRetryCodes are the result codes retried when RetryPolicy.RetryCodes
+// is nil: 2400 "command failed" and 2500 "command failed; server closing
+// connection", which is only retried when the client can reconnect.
+var DefaultRetryCodes = []string{ierr.CodeCommandFailed, ierr.CodeCommandFailedServerClosing}
+
+// NoJitter disables the random spread of retry delays when set as
+// RetryPolicy.Jitter, whose zero value selects the default.
+const NoJitter = -1.0
+
+// ReconcileFunc inspects the registry after an attempt of a non-idempotent
+// command failed with a retryable result or a lost response, and reports
+// whether that attempt was applied anyway. command is the EPP element such
+// as "domain:create", object the domain name or contact ID. It runs without
+// the session lock, so it may issue commands like InfoDomain on the same
+// client.
+type ReconcileFunc func(ctx context.Context, command, object string, cause error) (applied bool, err error)
+
+// RetryPolicy repeats commands that failed transiently. Idempotent commands
+// (check, info, hello, poll req, transfer query) are retried automatically;
+// create, update, delete, transfer, withdraw and poll ack are only retried
+// when Reconcile is set and reports that the failed attempt was not applied.
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts including the first; zero or one disables retries
+	InitialBackoff time.Duration // Delay before the second attempt, defaults to 500ms
+	MaxBackoff     time.Duration // Upper bound for the delay between attempts, defaults to 30s
+	Multiplier     float64       // Growth factor of the delay per attempt, defaults to 2
+	Jitter         float64       // Random spread of each delay as a fraction (0-1), defaults to 0.2; NoJitter disables it
+	RetryCodes     []string      // Result codes to retry, defaults to DefaultRetryCodes
+	Reconcile      ReconcileFunc // Opts non-idempotent commands into retries
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.InitialBackoff == 0 {
+		p.InitialBackoff = 500 * time.Millisecond
+	}
+	if p.MaxBackoff == 0 {
+		p.MaxBackoff = 30 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.Jitter == 0 {
+		p.Jitter = 0.2
+	}
+	// NoJitter, like any negative value, ends up as no spread.
+	p.Jitter = math.Min(math.Max(p.Jitter, 0), 1)
+	if p.RetryCodes == nil {
+		p.RetryCodes = DefaultRetryCodes
+	}
+	return p
+}
+
+// backoff returns the delay before the attempt following attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	delay = math.Min(delay, float64(p.MaxBackoff))
+	delay += (rand.Float64()*2 - 1) * p.Jitter * delay
+	return time.Duration(delay)
+}
+
+func (p RetryPolicy) retriesCode(code string) bool {
+	return contains(p.RetryCodes, code)
+}
+
+// sendRequest runs a command exchange, traced as one span, under the rate
+// limiter and the retry policy.
+func (c *Client) sendRequest(ctx context.Context, cmd command, request []byte) (*reply, error) {
+	ctx, finish := c.startSpan(ctx, cmd, request)
+	reply, err := c.sendAttempts(ctx, cmd, request)
+	finish(reply, err)
+	return reply, err
+}
+
+// sendAttempts performs the attempts of a command. Every attempt takes a
+// token before it queues for the session lock, and the lock is released
+// between attempts so other callers and the reconciliation function can use
+// the client while it backs off.
+func (c *Client) sendAttempts(ctx context.Context, cmd command, request []byte) (*reply, error) {
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.Wait(ctx, cmd.typ); err != nil {
+			return nil, err
+		}
+
+		if err := c.mu.LockContext(ctx); err != nil {
+			return nil, err
+		}
+		reply, err := c.roundTrip(ctx, cmd, request)
+		retry := c.shouldRetry(ctx, cmd, attempt, reply, err)
+		c.mu.Unlock()
+
+		if !retry {
+			return reply, err
+		}
+
+		if !cmd.idempotent {
+			cause := err
+			if cause == nil {
+				cause = newEPPError(cmd.name, reply)
+			}
+			applied, reconcileErr := c.retry.Reconcile(ctx, cmd.name, cmd.object, cause)
+			if reconcileErr != nil {
+				return nil, fmt.Errorf("failed to reconcile %s %s: %w (attempt failed: %v)", cmd.name, cmd.object, reconcileErr, cause)
+			}
+			if applied {
+				return nil, fmt.Errorf("%w: %s %s (attempt failed: %v)", ErrReconciled, cmd.name, cmd.object, cause)
+			}
+		}
+
+		timer := time.NewTimer(c.retry.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return reply, err
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry decides whether a finished attempt is repeated. Transport
+// failures are only retried when the session can be restored, and result
+// codes only when the session can carry another command. The caller must
+// hold c.mu.
+func (c *Client) shouldRetry(ctx context.Context, cmd command, attempt int, reply *reply, err error) bool {
+	policy := c.retry
+	if attempt >= policy.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+	if cmd.typ == CommandLogin || cmd.typ == CommandLogout {
+		return false
+	}
+	if !cmd.idempotent && policy.Reconcile == nil {
+		return false
+	}
+	if errors.Is(err, ErrInvalidState) || errors.Is(err, ErrExtensionNotNegotiated) {
+		// Nothing was sent, and another attempt would be refused alike.
+		return false
+	}
+	if err != nil {
+		return c.canReconnect()
+	}
+	if !policy.retriesCode(reply.code()) {
+		return false
+	}
+	return c.canReconnect() || (c.conn != nil && !c.poisoned)
+}
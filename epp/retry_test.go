@@ -0,0 +1,158 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+		Jitter:         NoJitter,
+	}.withDefaults()
+
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, delay := range want {
+		if got := policy.backoff(i + 1); got != delay {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, delay)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff(1) with jitter 0.5 = %s, want 50ms to 150ms", got)
+		}
+	}
+
+	if defaults := (RetryPolicy{}).withDefaults(); defaults.Jitter != 0.2 {
+		t.Errorf("default Jitter = %v, want 0.2", defaults.Jitter)
+	}
+}
+
+// retryServer answers the commands containing element with the given codes
+// in turn, the last one repeated, and everything else with 1000. It counts
+// the commands containing element.
+type retryServer struct {
+	element string
+	codes   []string
+
+	mu    sync.Mutex
+	count int
+}
+
+func (s *retryServer) transport(t *testing.T) Transport {
+	return testServer(t, func(request string) string {
+		if !strings.Contains(request, s.element) {
+			return testResponse(request, "1000", "")
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		code := s.codes[min(s.count, len(s.codes)-1)]
+		s.count++
+		return testResponse(request, code, "")
+	})
+}
+
+func (s *retryServer) commands() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+func newRetryClient(t *testing.T, transport Transport, policy RetryPolicy) *Client {
+	t.Helper()
+
+	policy.InitialBackoff = time.Millisecond
+	policy.Jitter = NoJitter
+	client := NewClient(Config{Username: "user", Password: "secret", Transport: transport, Retry: policy})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := client.Login(); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRetryIdempotentCommandUpToMaxAttempts(t *testing.T) {
+	server := &retryServer{element: "<domain:check", codes: []string{"2400"}}
+	client := newRetryClient(t, server.transport(t), RetryPolicy{MaxAttempts: 3})
+
+	_, err := client.CheckDomain([]string{"example.at"})
+	var eppErr *EPPError
+	if !errors.As(err, &eppErr) || eppErr.Code != "2400" {
+		t.Fatalf("CheckDomain returned %v, want the 2400 of the last attempt", err)
+	}
+	if n := server.commands(); n != 3 {
+		t.Errorf("server saw %d checks, want 3", n)
+	}
+}
+
+func TestRetryIdempotentCommandSucceedsAfterFailure(t *testing.T) {
+	server := &retryServer{element: "<domain:check", codes: []string{"2400", "1000"}}
+	client := newRetryClient(t, server.transport(t), RetryPolicy{MaxAttempts: 3})
+
+	if _, err := client.CheckDomain([]string{"example.at"}); err != nil {
+		t.Fatalf("CheckDomain: %v", err)
+	}
+	if n := server.commands(); n != 2 {
+		t.Errorf("server saw %d checks, want 2", n)
+	}
+}
+
+func TestRetryNonIdempotentCommandWithoutReconcile(t *testing.T) {
+	server := &retryServer{element: "<contact:delete", codes: []string{"2400"}}
+	client := newRetryClient(t, server.transport(t), RetryPolicy{MaxAttempts: 3})
+
+	if _, err := client.DeleteContact("C1"); err == nil {
+		t.Fatal("DeleteContact succeeded, want the 2400")
+	}
+	if n := server.commands(); n != 1 {
+		t.Errorf("server saw %d deletes, want 1", n)
+	}
+}
+
+func TestRetryReconcile(t *testing.T) {
+	tests := []struct {
+		name       string
+		applied    bool
+		wantErr    error
+		wantDelete int
+	}{
+		{name: "applied", applied: true, wantErr: ErrReconciled, wantDelete: 1},
+		{name: "not applied", applied: false, wantDelete: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &retryServer{element: "<contact:delete", codes: []string{"2400", "1000"}}
+			var calls []string
+			policy := RetryPolicy{
+				MaxAttempts: 3,
+				Reconcile: func(ctx context.Context, command, object string, cause error) (bool, error) {
+					calls = append(calls, command+" "+object)
+					return tt.applied, nil
+				},
+			}
+			client := newRetryClient(t, server.transport(t), policy)
+
+			_, err := client.DeleteContact("C1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteContact returned %v, want %v", err, tt.wantErr)
+			}
+			if n := server.commands(); n != tt.wantDelete {
+				t.Errorf("server saw %d deletes, want %d", n, tt.wantDelete)
+			}
+			if len(calls) != 1 || calls[0] != "contact:delete C1" {
+				t.Errorf("Reconcile calls = %q, want [contact:delete C1]", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package epp
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+)
+
+// Password length limits of the EPP pwType (RFC 5730).
+const (
+	MinPasswordLength = 6
+	MaxPasswordLength = 16
+)
+
+// DefaultPasswordSymbols are the special characters GeneratePassword draws
+// from unless the policy names others. They need no escaping in XML or JSON
+// and are left alone by shells, including the tilde expansion after "=" and
+// ":" of an unquoted assignment in a sourced environment file, by history
+// expansion and by globbing.
+const DefaultPasswordSymbols = "%+-.=@_"
+
+// PasswordPolicy describes the passwords RotatePassword generates. Every
+// generated password contains at least one lower-case letter, upper-case
+// letter, digit and symbol.
+type PasswordPolicy struct {
+	Length  int    // Defaults to MaxPasswordLength
+	Symbols string // Defaults to DefaultPasswordSymbols
+}
+
+// GeneratePassword returns a random password satisfying policy, drawn from
+// crypto/rand.
+func GeneratePassword(policy PasswordPolicy) (string, error) {
+	if policy.Length == 0 {
+		policy.Length = MaxPasswordLength
+	}
+	if policy.Symbols == "" {
+		policy.Symbols = DefaultPasswordSymbols
+	}
+	if policy.Length < MinPasswordLength || policy.Length > MaxPasswordLength {
+		return "", fmt.Errorf("EPP password length must be %d to %d, got %d", MinPasswordLength, MaxPasswordLength, policy.Length)
+	}
+
+	classes := []string{
+		"abcdefghijklmnopqrstuvwxyz",
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+		"0123456789",
+		policy.Symbols,
+	}
+	all := strings.Join(classes, "")
+
+	password := make([]byte, policy.Length)
+	for i := range password {
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		b, err := randomChoice(set)
+		if err != nil {
+			return "", err
+		}
+		password[i] = b
+	}
+
+	// Move the mandatory characters to random positions.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+func randomChoice(set string) (byte, error) {
+	i, err := randomInt(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[i], nil
+}
+
+func randomInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate EPP password: %w", err)
+	}
+	return int(i.Int64()), nil
+}
+
+// PasswordRotationError is returned when the registry accepted a new
+// password but it could not be persisted. RolledBack reports whether the
+// old password was restored at the registry; if not, Password is the one
+// the registry now expects and has to be saved by other means. Error never
+// includes it.
+type PasswordRotationError struct {
+	Err         error // Why persisting failed
+	RolledBack  bool
+	RollbackErr error // Why restoring the old password failed, if it did
+	Password    string
+}
+
+func (e *PasswordRotationError) Error() string {
+	if e.RolledBack {
+		return fmt.Sprintf("EPP password change rolled back, new password could not be stored: %v", e.Err)
+	}
+	return fmt.Sprintf("EPP password changed but neither stored nor rolled back: %v (rollback: %v)", e.Err, e.RollbackErr)
+}
+
+func (e *PasswordRotationError) Unwrap() error {
+	return e.Err
+}
+
+// RotatePassword generates a new password according to policy and changes
+// it like ChangePassword. Config.Credentials must be a CredentialStore, so
+// that the generated password is never only held in memory.
+func (c *Client) RotatePassword(policy PasswordPolicy) error {
+	return c.RotatePasswordContext(context.Background(), policy)
+}
+
+func (c *Client) RotatePasswordContext(ctx context.Context, policy PasswordPolicy) error {
+	if _, ok := c.credentials.(CredentialStore); !ok {
+		return ErrCredentialsReadOnly
+	}
+
+	newPassword, err := GeneratePassword(policy)
+	if err != nil {
+		return err
+	}
+
+	if err := c.mu.LockContext(ctx); err != nil {
+		return err
+	}
+	defer c.mu.Unlock()
+
+	return c.rotatePassword(ctx, newPassword)
+}
+
+// rotatePassword changes the password at the registry, persists it and
+// rolls the change back when persisting fails. The caller must hold c.mu.
+func (c *Client) rotatePassword(ctx context.Context, newPassword string) error {
+	store, ok := c.credentials.(CredentialStore)
+	if _, static := c.credentials.(StaticCredentials); !ok && !static {
+		return ErrCredentialsReadOnly
+	}
+
+	credentials, err := c.currentCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := c.changePassword(ctx, credentials, newPassword); err != nil {
+		return err
+	}
+	if !ok {
+		// Static credentials only live in memory.
+		return nil
+	}
+
+	// The registry already switched passwords; finish even if ctx ends now.
+	ctx = context.WithoutCancel(ctx)
+	storeErr := store.StorePassword(ctx, newPassword)
+	if storeErr == nil {
+		c.activePassword = ""
+		return nil
+	}
+
+	rollbackErr := c.rollbackPassword(ctx, credentials, newPassword)
+	return &PasswordRotationError{
+		Err:         storeErr,
+		RolledBack:  rollbackErr == nil,
+		RollbackErr: rollbackErr,
+		Password:    newPassword,
+	}
+}
+
+// rollbackPassword restores the old password on a new session, because
+// newPW is only accepted at login. The caller must hold c.mu.
+func (c *Client) rollbackPassword(ctx context.Context, old Credentials, newPassword string) error {
+	// The caller's ID belongs to the password change being rolled back.
+	ctx = withoutClTRID(ctx)
+
+	c.stopKeepalive()
+	if err := c.logout(ctx); err != nil {
+		c.logf(ctx, slog.LevelWarn, "EPP logout before password rollback failed", "error", err)
+	}
+	if err := c.connect(ctx); err != nil {
+		return err
+	}
+
+	current := Credentials{Username: old.Username, Password: newPassword}
+	if err := c.changePassword(ctx, current, old.Password); err != nil {
+		return err
+	}
+
+	c.activePassword = ""
+	return nil
+}
+
+// currentCredentials returns the credentials for the next login: those of
+// the provider, with a password changed on this client that has not been
+// persisted yet. The caller must hold c.mu.
+func (c *Client) currentCredentials(ctx context.Context) (Credentials, error) {
+	credentials, err := c.credentials.Credentials(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to obtain EPP credentials: %w", err)
+	}
+	if c.activePassword != "" {
+		credentials.Password = c.activePassword
+	}
+	return credentials, nil
+}
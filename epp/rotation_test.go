@@ -0,0 +1,124 @@
+package epp
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// failingStore provides fixed credentials and fails to persist passwords.
+type failingStore struct {
+	credentials Credentials
+	err         error
+}
+
+func (s failingStore) Credentials(ctx context.Context) (Credentials, error) {
+	return s.credentials, nil
+}
+
+func (s failingStore) StorePassword(ctx context.Context, password string) error {
+	return s.err
+}
+
+// passwordChange is a login with newPW as seen by the server.
+type passwordChange struct {
+	clTRID, pw, newPW string
+}
+
+func TestRotatePasswordRollsBackWhenStoreFails(t *testing.T) {
+	pwPattern := regexp.MustCompile(`<pw>([^<]*)</pw>`)
+	newPWPattern := regexp.MustCompile(`<newPW>([^<]*)</newPW>`)
+	logoutPattern := regexp.MustCompile(`<logout\s*/>|<logout></logout>`)
+
+	var (
+		mu       sync.Mutex
+		password = "old-secret"
+		changes  []passwordChange
+	)
+	transport := testServer(t, func(request string) string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch match := pwPattern.FindStringSubmatch(request); {
+		case logoutPattern.MatchString(request):
+			return testResponse(request, "1500", "")
+		case match == nil:
+			return testResponse(request, "1000", "")
+		case match[1] != password:
+			return testResponse(request, "2200", "")
+		}
+		if newPW := newPWPattern.FindStringSubmatch(request); newPW != nil {
+			var clTRID string
+			if id := clTRIDPattern.FindStringSubmatch(request); id != nil {
+				clTRID = id[1]
+			}
+			changes = append(changes, passwordChange{clTRID: clTRID, pw: password, newPW: newPW[1]})
+			password = newPW[1]
+		}
+		return testResponse(request, "1000", "")
+	})
+
+	storeErr := errors.New("vault unavailable")
+	client := NewClient(Config{
+		Credentials: failingStore{Credentials{Username: "user", Password: "old-secret"}, storeErr},
+		Transport:   transport,
+	})
+	if err := client.Connect(); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer client.Close()
+
+	err := client.RotatePasswordContext(WithClTRID(context.Background(), "rotate-1"), PasswordPolicy{})
+
+	var rotationErr *PasswordRotationError
+	if !errors.As(err, &rotationErr) {
+		t.Fatalf("RotatePassword returned %v, want a *PasswordRotationError", err)
+	}
+	if !rotationErr.RolledBack {
+		t.Errorf("RolledBack = false, rollback error: %v", rotationErr.RollbackErr)
+	}
+	if !errors.Is(err, storeErr) {
+		t.Errorf("error %v does not wrap the store error", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 2 {
+		t.Fatalf("server saw %d password changes, want 2: %+v", len(changes), changes)
+	}
+	generated := rotationErr.Password
+	if changes[0].pw != "old-secret" || changes[0].newPW != generated {
+		t.Errorf("first change %s -> %s, want old-secret -> %s", changes[0].pw, changes[0].newPW, generated)
+	}
+	if changes[1].pw != generated || changes[1].newPW != "old-secret" {
+		t.Errorf("rollback %s -> %s, want %s -> old-secret", changes[1].pw, changes[1].newPW, generated)
+	}
+	if changes[0].clTRID != "rotate-1" || changes[1].clTRID == "rotate-1" {
+		t.Errorf("clTRIDs %q and %q, want the caller's only on the first change", changes[0].clTRID, changes[1].clTRID)
+	}
+	if password != "old-secret" {
+		t.Errorf("server password after rollback = %q, want old-secret", password)
+	}
+}
+
+func TestGeneratePasswordAvoidsShellExpansion(t *testing.T) {
+	// Characters a shell may expand or interpret in an unquoted assignment
+	// such as EPP_PASSWORD=... in a sourced environment file.
+	const shellSpecial = "~:$`\\\"' \t\n;&|<>(){}[]*?!#"
+
+	if strings.ContainsAny(DefaultPasswordSymbols, shellSpecial) {
+		t.Errorf("DefaultPasswordSymbols %q contains shell-expanding characters", DefaultPasswordSymbols)
+	}
+	for i := 0; i < 200; i++ {
+		password, err := GeneratePassword(PasswordPolicy{})
+		if err != nil {
+			t.Fatalf("GeneratePassword: %v", err)
+		}
+		if strings.ContainsAny(password, shellSpecial) {
+			t.Fatalf("generated password %q contains shell-expanding characters", password)
+		}
+	}
+}
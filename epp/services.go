@@ -0,0 +1,105 @@
+package epp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	NamespaceDomain       = "urn:ietf:params:xml:ns:domain-1.0"
+	NamespaceContact      = "urn:ietf:params:xml:ns:contact-1.0"
+	NamespaceSecDNS       = "urn:ietf:params:xml:ns:secDNS-1.1"
+	NamespaceAtExtEPP     = "http://www.nic.at/xsd/at-ext-epp-1.0"
+	NamespaceAtExtDomain  = "http://www.nic.at/xsd/at-ext-domain-1.0"
+	NamespaceAtExtContact = "http://www.nic.at/xsd/at-ext-contact-1.0"
+)
+
+var (
+	ErrServiceNotOffered      = errors.New("EPP service not offered by server")
+	ErrExtensionNotNegotiated = errors.New("EPP extension not negotiated for this session")
+)
+
+// DefaultObjectURIs and DefaultExtensionURIs are announced at login unless
+// Config overrides them.
+var (
+	DefaultObjectURIs = []string{
+		NamespaceDomain,
+		NamespaceContact,
+	}
+	DefaultExtensionURIs = []string{
+		NamespaceAtExtEPP,
+		NamespaceAtExtDomain,
+		NamespaceAtExtContact,
+		NamespaceSecDNS,
+	}
+)
+
+// loginServices intersects the configured services with the service menu of
+// the greeting. It fails when a required service is not offered. The caller
+// must hold c.mu.
+func (c *Client) loginServices() (LoginServices, error) {
+	objURIs := c.objectURIs
+	extURIs := c.extensionURIs
+	if c.greeting != nil {
+		objURIs = intersect(objURIs, c.greeting.ObjectURIs())
+		extURIs = intersect(extURIs, c.greeting.ExtensionURIs())
+	}
+
+	var missing []string
+	for _, uri := range c.requiredURIs {
+		if !contains(objURIs, uri) && !contains(extURIs, uri) {
+			missing = append(missing, uri)
+		}
+	}
+	if len(missing) > 0 {
+		return LoginServices{}, fmt.Errorf("%w: %s", ErrServiceNotOffered, strings.Join(missing, ", "))
+	}
+
+	services := LoginServices{ObjURI: objURIs}
+	if len(extURIs) > 0 {
+		services.SvcExtension = &LoginServiceExtension{ExtURI: extURIs}
+	}
+	return services, nil
+}
+
+// establishSession records a successful login. The caller must hold c.mu.
+func (c *Client) establishSession(services LoginServices) {
+	c.loggedIn = true
+	c.extensions = nil
+	if services.SvcExtension != nil {
+		c.extensions = services.SvcExtension.ExtURI
+	}
+	c.updateState()
+	c.startKeepalive()
+}
+
+// checkExtensions refuses to send a command using an extension that was not
+// announced at login for the current session. The caller must hold c.mu.
+func (c *Client) checkExtensions(cmd command) error {
+	for _, uri := range cmd.extensions {
+		if !contains(c.extensions, uri) {
+			return fmt.Errorf("%w: %s", ErrExtensionNotNegotiated, uri)
+		}
+	}
+	return nil
+}
+
+func intersect(wanted, offered []string) []string {
+	var result []string
+	for _, uri := range wanted {
+		if contains(offered, uri) {
+			result = append(result, uri)
+		}
+	}
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package epp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	ierr "github.com/ParadoxTR/epp-at-go/internal/errors"
+)
+
+func (c *Client) canReconnect() bool {
+	return c.autoReconnect && c.loggedIn
+}
+
+// reconnect replaces a lost session: it re-dials, consumes the greeting and
+// logs in again with the stored credentials. The caller must hold c.mu.
+func (c *Client) reconnect(ctx context.Context) error {
+	err := c.restoreSession(ctx)
+	if c.metrics != nil {
+		c.metrics.ObserveReconnect(err)
+	}
+	return err
+}
+
+func (c *Client) restoreSession(ctx context.Context) error {
+	c.logf(ctx, slog.LevelWarn, "EPP session lost, reconnecting", "host", c.hostname)
+	c.dropConnection()
+
+	if err := c.connect(ctx); err != nil {
+		return fmt.Errorf("failed to reconnect to EPP server: %w", err)
+	}
+
+	// The fresh session is not logged in yet, whatever the old one was.
+	c.loggedIn = false
+	if err := c.login(withoutClTRID(ctx)); err != nil {
+		// Never keep retrying a rejected login, it would lock the account.
+		c.loggedIn = false
+		c.dropConnection()
+		return fmt.Errorf("failed to re-login to EPP server: %w", err)
+	}
+
+	return nil
+}
+
+// shouldRetryAfterSessionLoss reports whether a command may be replayed on a
+// fresh session. Only idempotent commands are replayed because a lost
+// response leaves it unknown whether the registry executed the command.
+func (c *Client) shouldRetryAfterSessionLoss(ctx context.Context, cmd command, reply *reply, err error) bool {
+	if !c.canReconnect() || !cmd.idempotent || ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	// Replaying after 2501 would only repeat the rejected authentication.
+	code := reply.code()
+	return ClassifyResultCode(code) == ResultSessionClosing && code != ierr.CodeAuthenticationErrorServerClosing
+}
+
+func sessionLossCause(reply *reply, err error) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("server closing connection with result %s", reply.code())
+}
+
+// usable reports whether the session can carry another command, either
+// directly or by restoring itself.
+func (c *Client) usable() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loggedIn {
+		return false
+	}
+	return c.canReconnect() || (c.conn != nil && !c.poisoned)
+}
+
+func (c *Client) idleSince() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lastActivity
+}
@@ -0,0 +1,92 @@
+package epp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SessionState is the lifecycle stage of a Client's session.
+type SessionState int32
+
+const (
+	StateDisconnected  SessionState = iota // No connection, or the connection was lost or poisoned
+	StateGreeted                           // Connected and greeted, not logged in
+	StateAuthenticated                     // Logged in, commands may be sent
+	StateClosing                           // Logout in progress
+)
+
+func (s SessionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateGreeted:
+		return "greeted"
+	case StateAuthenticated:
+		return "authenticated"
+	case StateClosing:
+		return "closing"
+	default:
+		return fmt.Sprintf("SessionState(%d)", int32(s))
+	}
+}
+
+// ErrInvalidState is matched by errors.Is against a *StateError.
+var ErrInvalidState = errors.New("EPP command not allowed in current session state")
+
+// StateError is returned without sending anything when a command is not
+// allowed in the session's current state, e.g. a domain check before Login.
+type StateError struct {
+	Command string
+	State   SessionState
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("EPP %s not allowed while session is %s", e.Command, e.State)
+}
+
+func (e *StateError) Is(target error) bool {
+	return target == ErrInvalidState
+}
+
+// State returns the current session state. It does not wait for a command
+// in flight, so it reports StateClosing while a logout is running.
+func (c *Client) State() SessionState {
+	return SessionState(c.state.Load())
+}
+
+// updateState publishes the state derived from the session fields. The
+// caller must hold c.mu and call it after every change to them.
+func (c *Client) updateState() {
+	state := StateGreeted
+	switch {
+	case c.closing:
+		state = StateClosing
+	case c.conn == nil || c.poisoned:
+		state = StateDisconnected
+	case c.loggedIn:
+		state = StateAuthenticated
+	}
+	c.state.Store(int32(state))
+}
+
+// checkState rejects a command the session cannot carry. A lost session of
+// a resilient client still accepts commands because roundTrip restores it.
+// The caller must hold c.mu.
+func (c *Client) checkState(cmd command) error {
+	var allowed bool
+	switch {
+	case c.closing:
+		allowed = cmd.typ == CommandLogout
+	case cmd.typ == CommandLogin:
+		allowed = c.conn != nil && !c.loggedIn
+	case cmd.typ == CommandHello:
+		allowed = c.conn != nil || c.canReconnect()
+	default:
+		allowed = c.loggedIn && (c.conn != nil || c.canReconnect())
+	}
+
+	if !allowed {
+		return &StateError{Command: cmd.name, State: c.State()}
+	}
+	return nil
+}
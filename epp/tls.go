@@ -0,0 +1,98 @@
+package epp
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+// TLSOptions configures the TLS session used to reach the registry. All
+// fields are optional; the zero value verifies the server against the
+// system roots with TLS 1.2 or newer.
+type TLSOptions struct {
+	Config     *tls.Config // Base configuration, cloned before use
+	CertFile   string      // PEM client certificate
+	KeyFile    string      // PEM client private key
+	CAFile     string      // PEM CA bundle replacing the system roots (e.g. nic.at OT&E)
+	MinVersion uint16      // Minimum TLS version, defaults to tls.VersionTLS12
+	// PinnedPublicKeys lists base64-encoded SHA-256 hashes of the server
+	// certificate's SubjectPublicKeyInfo. When set, the leaf certificate must
+	// match one of them in addition to passing chain verification.
+	PinnedPublicKeys []string
+}
+
+func (options TLSOptions) build(serverName string) (*tls.Config, error) {
+	var config *tls.Config
+	if options.Config != nil {
+		config = options.Config.Clone()
+	} else {
+		config = &tls.Config{}
+	}
+
+	if config.ServerName == "" {
+		config.ServerName = serverName
+	}
+
+	if options.MinVersion != 0 {
+		config.MinVersion = options.MinVersion
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS client certificate: %w", err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+
+	if options.CAFile != "" {
+		pem, err := os.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", options.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if len(options.PinnedPublicKeys) > 0 {
+		pins := make(map[string]struct{}, len(options.PinnedPublicKeys))
+		for _, pin := range options.PinnedPublicKeys {
+			pins[pin] = struct{}{}
+		}
+		next := config.VerifyConnection
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if err := verifyPinnedPublicKey(state, pins); err != nil {
+				return err
+			}
+			if next != nil {
+				return next(state)
+			}
+			return nil
+		}
+	}
+
+	return config, nil
+}
+
+func verifyPinnedPublicKey(state tls.ConnectionState, pins map[string]struct{}) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("server presented no certificate to verify against pinned public keys")
+	}
+
+	sum := sha256.Sum256(state.PeerCertificates[0].RawSubjectPublicKeyInfo)
+	fingerprint := base64.StdEncoding.EncodeToString(sum[:])
+	if _, ok := pins[fingerprint]; !ok {
+		return fmt.Errorf("server public key %s does not match any pinned public key", fingerprint)
+	}
+
+	return nil
+}
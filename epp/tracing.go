@@ -0,0 +1,75 @@
+package epp
+
+import (
+	"context"
+)
+
+// Span attribute keys set on every command span.
+const (
+	AttrCommand    = "epp.command"
+	AttrObject     = "epp.object"
+	AttrClTRID     = "epp.cltrid"
+	AttrSvTRID     = "epp.svtrid"
+	AttrResultCode = "epp.result_code"
+)
+
+// Tracer starts spans. It is deliberately small so that an OpenTelemetry
+// tracer, or any other, can be adapted without the library depending on it.
+// The returned context carries the span, so exchanges, reconnects and
+// interceptors of the command run inside it.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is one traced operation.
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error) // Marks the span as failed
+	End()
+}
+
+// startSpan opens the span of a command, named "EPP <command>", as a child
+// of any span in ctx. The returned function records the outcome and ends
+// the span; it must be called exactly once.
+func (c *Client) startSpan(ctx context.Context, cmd command, request []byte) (context.Context, func(reply *reply, err error)) {
+	if c.tracer == nil {
+		return ctx, func(*reply, error) {}
+	}
+
+	ctx, span := c.tracer.Start(ctx, "EPP "+cmd.name)
+	span.SetAttribute(AttrCommand, cmd.name)
+	if cmd.object != "" {
+		span.SetAttribute(AttrObject, cmd.object)
+	}
+	if clTRID := requestClTRID(request); clTRID != "" {
+		span.SetAttribute(AttrClTRID, clTRID)
+	}
+
+	return ctx, func(reply *reply, err error) {
+		defer span.End()
+
+		if err != nil {
+			span.RecordError(err)
+			return
+		}
+		if svTRID := reply.trID.SvTRID; svTRID != "" {
+			span.SetAttribute(AttrSvTRID, svTRID)
+		}
+		if code := reply.code(); code != "" {
+			span.SetAttribute(AttrResultCode, code)
+			if ClassifyResultCode(code) != ResultSuccess {
+				span.RecordError(spanError(cmd.name, reply))
+			}
+		}
+	}
+}
+
+// spanError is the *EPPError of a failed response without its reasons and
+// conditions, which echo submitted values such as registrant data that must
+// not reach the tracing backend.
+func spanError(command string, reply *reply) *EPPError {
+	e := newEPPError(command, reply)
+	e.Reasons = nil
+	e.Conditions = nil
+	return e
+}
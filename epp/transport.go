@@ -0,0 +1,129 @@
+package epp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrTransportExhausted is returned by a ConnTransport that has already
+// handed out its connection, for example when a resilient client tries to
+// reconnect.
+var ErrTransportExhausted = errors.New("EPP transport has no further connections")
+
+// Transport opens the stream an EPP session runs over, ready for the first
+// frame. The default transport dials the configured host through
+// Config.Dialer and performs the TLS handshake; a custom Transport replaces
+// both, so TLSOptions do not apply to it. The returned connection must
+// support deadlines, which is how commands are bounded and cancelled.
+type Transport interface {
+	Dial(ctx context.Context) (net.Conn, error)
+}
+
+// TransportFunc adapts a function to the Transport interface.
+type TransportFunc func(ctx context.Context) (net.Conn, error)
+
+func (f TransportFunc) Dial(ctx context.Context) (net.Conn, error) {
+	return f(ctx)
+}
+
+// Dialer opens the TCP connection underneath TLS. *net.Dialer satisfies it,
+// and ProxyDialer returns one that tunnels through a SOCKS5 or HTTP CONNECT
+// proxy.
+type Dialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// DialerFunc adapts a function to the Dialer interface.
+type DialerFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+func (f DialerFunc) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return f(ctx, network, address)
+}
+
+// tlsTransport is the default transport: a TCP stream from dialer wrapped in
+// a TLS client connection.
+type tlsTransport struct {
+	address string
+	dialer  Dialer
+	config  *tls.Config
+	timeout time.Duration // Bounds dialing and the handshake together
+}
+
+func (t *tlsTransport) Dial(ctx context.Context) (net.Conn, error) {
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+
+	rawConn, err := t.dialer.DialContext(ctx, "tcp", t.address)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := tls.Client(rawConn, t.config)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		rawConn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// ConnTransport hands out an already established connection, for example
+// one set up by a connection broker. It can be dialed only once; later dials
+// fail with ErrTransportExhausted.
+func ConnTransport(conn net.Conn) Transport {
+	var once sync.Once
+	return TransportFunc(func(ctx context.Context) (net.Conn, error) {
+		var handed net.Conn
+		once.Do(func() { handed = conn })
+		if handed == nil {
+			return nil, ErrTransportExhausted
+		}
+		return handed, nil
+	})
+}
+
+// PipeTransport connects the client to an in-process server over net.Pipe.
+// Every dial starts serve with the server end of a new pipe, so the client
+// can run and reconnect without any network, which is convenient for tests.
+// serve must send the greeting first and owns the connection it is given.
+func PipeTransport(serve func(conn net.Conn)) Transport {
+	return TransportFunc(func(ctx context.Context) (net.Conn, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		client, server := net.Pipe()
+		go serve(server)
+		return client, nil
+	})
+}
+
+func (c *Client) transportFor() (Transport, error) {
+	if c.transport != nil {
+		return c.transport, nil
+	}
+
+	tlsConfig, err := c.tls.build(c.hostname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build TLS configuration: %w", err)
+	}
+
+	dialer := c.dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	return &tlsTransport{
+		address: net.JoinHostPort(c.hostname, fmt.Sprint(c.port)),
+		dialer:  dialer,
+		config:  tlsConfig,
+		timeout: c.timeout,
+	}, nil
+}
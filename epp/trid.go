@@ -0,0 +1,167 @@
+package epp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// Client transaction ID length limits of the EPP trIDStringType.
+const (
+	MinClTRIDLength = 3
+	MaxClTRIDLength = 64
+)
+
+// ErrInvalidClTRID is returned before sending when a client transaction ID
+// violates the EPP limits.
+var ErrInvalidClTRID = errors.New("invalid EPP client transaction ID")
+
+// TrIDGenerator produces client transaction IDs. Implementations must be
+// safe for concurrent use and must not repeat an ID; Config.TrIDPrefix is
+// prepended to every generated ID.
+type TrIDGenerator interface {
+	NewTrID() string
+}
+
+// TrIDGeneratorFunc adapts a function to the TrIDGenerator interface.
+type TrIDGeneratorFunc func() string
+
+func (f TrIDGeneratorFunc) NewTrID() string {
+	return f()
+}
+
+// MonotonicTrIDGenerator returns a generator of short IDs made of a random
+// instance tag and a counter, e.g. "3f9a0c1e-42". IDs are ordered within
+// one generator and distinct across generators.
+func MonotonicTrIDGenerator() TrIDGenerator {
+	var tag [4]byte
+	mustReadRandom(tag[:])
+	return &monotonicGenerator{tag: hex.EncodeToString(tag[:])}
+}
+
+type monotonicGenerator struct {
+	tag     string
+	counter atomic.Uint64
+}
+
+func (g *monotonicGenerator) NewTrID() string {
+	return g.tag + "-" + strconv.FormatUint(g.counter.Add(1), 10)
+}
+
+// ULIDTrIDGenerator returns a generator of ULIDs: 26 characters encoding a
+// millisecond timestamp and 80 random bits, sortable by creation time. IDs
+// created in the same millisecond increment the random part, so they stay
+// ordered and never repeat. This is the default generator.
+func ULIDTrIDGenerator() TrIDGenerator {
+	return &ulidGenerator{}
+}
+
+type ulidGenerator struct {
+	mu      sync.Mutex
+	lastMS  uint64
+	entropy [10]byte
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+func (g *ulidGenerator) NewTrID() string {
+	g.mu.Lock()
+	ms := uint64(time.Now().UnixMilli())
+	if ms > g.lastMS {
+		g.lastMS = ms
+		mustReadRandom(g.entropy[:])
+	} else {
+		// Same or earlier millisecond: keep the timestamp and count up.
+		ms = g.lastMS
+		for i := len(g.entropy) - 1; i >= 0; i-- {
+			g.entropy[i]++
+			if g.entropy[i] != 0 {
+				break
+			}
+		}
+	}
+	var id [16]byte
+	id[0], id[1] = byte(ms>>40), byte(ms>>32)
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], g.entropy[:])
+	g.mu.Unlock()
+
+	return encodeULID(id)
+}
+
+// encodeULID writes the 128 bits of id as 26 Crockford base32 digits, the
+// first of which carries only 3 bits.
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordBase32[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+func mustReadRandom(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("epp: reading random bytes failed: %v", err))
+	}
+}
+
+// ValidateClTRID checks a client transaction ID against the EPP limits: 3
+// to 64 characters without control characters or surrounding whitespace.
+func ValidateClTRID(id string) error {
+	if n := utf8.RuneCountInString(id); n < MinClTRIDLength || n > MaxClTRIDLength {
+		return fmt.Errorf("%w %q: must be %d to %d characters, got %d", ErrInvalidClTRID, id, MinClTRIDLength, MaxClTRIDLength, n)
+	}
+	if strings.TrimSpace(id) != id || strings.ContainsAny(id, "\t\r\n") {
+		return fmt.Errorf("%w %q: must not contain tabs, line breaks or surrounding whitespace", ErrInvalidClTRID, id)
+	}
+	return nil
+}
+
+type clTRIDKey struct{}
+
+// WithClTRID returns a context that makes every command sent with it use id
+// as its client transaction ID instead of a generated one, e.g. to correlate
+// with a request ID of the caller, so use a separate context per command
+// when the IDs should be unique. The ID is used verbatim, without
+// Config.TrIDPrefix, and validated before sending. Logins performed to
+// restore or open a session, or to roll back a password change, never use
+// it.
+func WithClTRID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, clTRIDKey{}, id)
+}
+
+// withoutClTRID hides an explicit client transaction ID from session-level
+// commands run on behalf of another command.
+func withoutClTRID(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(clTRIDKey{}).(string); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, clTRIDKey{}, "")
+}
+
+// transactionID returns the explicit client transaction ID of ctx or a new
+// one from the configured generator.
+func (c *Client) transactionID(ctx context.Context) (string, error) {
+	id, _ := ctx.Value(clTRIDKey{}).(string)
+	if id == "" {
+		id = c.trIDPrefix + c.trIDGenerator.NewTrID()
+	}
+	if err := ValidateClTRID(id); err != nil {
+		return "", err
+	}
+	return id, nil
+}
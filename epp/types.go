@@ -1,26 +1,156 @@
 package epp
 
 import (
+	"bytes"
 	"encoding/xml"
-	"fmt"
-	"math/rand"
-	"time"
 )
 
-func generateTransactionID() string {
-	return fmt.Sprintf("epp-go-%d-%d", time.Now().Unix(), rand.Int63n(10000))
+type Response struct {
+	XMLName        xml.Name `xml:"epp"`
+	ResponseStatus `xml:"-"`
+	Extension      *ResponseExtension `xml:"response>extension,omitempty"`
+	TrID           TrID               `xml:"response>trID"`
 }
 
-type Response struct {
-	XMLName   xml.Name           `xml:"epp"`
-	Result    Result             `xml:"response>result"`
-	Extension *ResponseExtension `xml:"response>extension,omitempty"`
-	TrID      TrID               `xml:"response>trID"`
+func (r *Response) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return unmarshalResponse(d, start, r)
+}
+
+func (r *Response) payload() any {
+	type response Response
+	return (*response)(r)
+}
+
+// ResponseStatus is embedded in every response type. Result repeats the
+// first entry of Results, which is the one that determines success. The
+// response types fill it in their UnmarshalXML, so xml.Unmarshal decodes
+// them completely.
+type ResponseStatus struct {
+	Result     Result      `xml:"-"`
+	Results    []Result    `xml:"response>result"`
+	Conditions []Condition `xml:"response>extension>conditions>condition"`
+}
+
+func (s *ResponseStatus) status() *ResponseStatus {
+	return s
+}
+
+// responseType is implemented by every response type. payload returns the
+// response as a type without the UnmarshalXML method, which decodes
+// everything but the ResponseStatus.
+type responseType interface {
+	status() *ResponseStatus
+	payload() any
+}
+
+// reply is a response frame with the summary every layer needs: results,
+// conditions, poll queue and transaction IDs. The summary is decoded once
+// when the frame is read, so that only the command-specific payload has to
+// be decoded again.
+type reply struct {
+	frame  []byte
+	status ResponseStatus
+	msgQ   *PollMessageQueue
+	trID   TrID
+}
+
+// newReply summarizes the response frame of cmd. Greetings, read for hello
+// and on connect, carry no result and are not summarized.
+func newReply(cmd command, frame []byte) *reply {
+	r := &reply{frame: frame}
+	if cmd.typ == CommandHello {
+		return r
+	}
+
+	var summary struct {
+		ResponseStatus
+		MsgQ *PollMessageQueue `xml:"response>msgQ"`
+		TrID TrID              `xml:"response>trID"`
+	}
+	if err := xml.Unmarshal(frame, &summary); err != nil {
+		return r
+	}
+	r.status = summary.ResponseStatus
+	if len(r.status.Results) > 0 {
+		r.status.Result = r.status.Results[0]
+	}
+	r.msgQ = summary.MsgQ
+	r.trID = summary.TrID
+	return r
+}
+
+// code returns the first result code, which determines success.
+func (r *reply) code() string {
+	return r.status.Result.Code
+}
+
+// decode unmarshals the payload of the frame into v and fills its
+// ResponseStatus from the summary.
+func (r *reply) decode(v responseType) error {
+	if err := xml.Unmarshal(r.frame, v.payload()); err != nil {
+		return err
+	}
+	*v.status() = r.status
+	return nil
+}
+
+// unmarshalResponse decodes the <epp> element at start into v and its
+// ResponseStatus. The status needs a separate pass because command-specific
+// extension fields would otherwise shadow the conditions, so the element is
+// read once and decoded twice.
+func unmarshalResponse(d *xml.Decoder, start xml.StartElement, v responseType) error {
+	var element struct {
+		Inner []byte `xml:",innerxml"`
+	}
+	if err := d.DecodeElement(&element, &start); err != nil {
+		return err
+	}
+	document := rebuildElement(start, element.Inner)
+
+	if err := xml.Unmarshal(document, v.payload()); err != nil {
+		return err
+	}
+	status := v.status()
+	if err := xml.Unmarshal(document, status); err != nil {
+		return err
+	}
+	if len(status.Results) > 0 {
+		status.Result = status.Results[0]
+	}
+
+	return nil
+}
+
+// rebuildElement wraps inner in the start tag it was read with, keeping the
+// namespace declarations so that prefixes inside remain bound.
+func rebuildElement(start xml.StartElement, inner []byte) []byte {
+	var b bytes.Buffer
+	b.WriteString("<" + start.Name.Local)
+	for _, attr := range start.Attr {
+		name := attr.Name.Local
+		switch attr.Name.Space {
+		case "":
+		case "xmlns":
+			name = "xmlns:" + name
+		default:
+			// Other prefixed attributes, such as xsi:schemaLocation, are
+			// not decoded.
+			continue
+		}
+		b.WriteString(" " + name + `="`)
+		xml.EscapeText(&b, []byte(attr.Value))
+		b.WriteString(`"`)
+	}
+	b.WriteString(">")
+	b.Write(inner)
+	b.WriteString("</" + start.Name.Local + ">")
+	return b.Bytes()
 }
 
 type Result struct {
-	Code string `xml:"code,attr"`
-	Msg  string `xml:"msg"`
+	Code      string     `xml:"code,attr"`
+	Msg       string     `xml:"msg"`
+	ExtValues []ExtValue `xml:"extValue"`
 }
 
 type TrID struct {
@@ -147,10 +277,19 @@ type DomainCheck struct {
 }
 
 type CheckDomainResponse struct {
-	XMLName xml.Name                `xml:"epp"`
-	Result  Result                  `xml:"response>result"`
-	ResData CheckDomainResponseData `xml:"response>resData"`
-	TrID    TrID                    `xml:"response>trID"`
+	XMLName        xml.Name `xml:"epp"`
+	ResponseStatus `xml:"-"`
+	ResData        CheckDomainResponseData `xml:"response>resData"`
+	TrID           TrID                    `xml:"response>trID"`
+}
+
+func (r *CheckDomainResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return unmarshalResponse(d, start, r)
+}
+
+func (r *CheckDomainResponse) payload() any {
+	type checkDomainResponse CheckDomainResponse
+	return (*checkDomainResponse)(r)
 }
 
 type CheckDomainResponseData struct {
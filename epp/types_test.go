@@ -0,0 +1,54 @@
+package epp
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalResponseFillsStatus(t *testing.T) {
+	frame := `<?xml version="1.0" encoding="UTF-8"?>
+<epp xmlns="urn:ietf:params:xml:ns:epp-1.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="urn:ietf:params:xml:ns:epp-1.0 epp-1.0.xsd">
+  <response>
+    <result code="2306">
+      <msg>Parameter value policy error</msg>
+      <extValue>
+        <value><domain:name xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">example.at</domain:name></value>
+        <reason>name reserved</reason>
+      </extValue>
+    </result>
+    <resData>
+      <domain:infData xmlns:domain="urn:ietf:params:xml:ns:domain-1.0">
+        <domain:name>example.at</domain:name>
+      </domain:infData>
+    </resData>
+    <extension>
+      <conditions xmlns="http://www.nic.at/xsd/at-ext-result-1.0">
+        <condition code="123" severity="error"><msg>reserved</msg></condition>
+      </conditions>
+    </extension>
+    <trID><clTRID>client-1</clTRID><svTRID>server-1</svTRID></trID>
+  </response>
+</epp>`
+
+	var response InfoDomainResponse
+	if err := xml.Unmarshal([]byte(frame), &response); err != nil {
+		t.Fatalf("xml.Unmarshal: %v", err)
+	}
+
+	if response.Result.Code != "2306" || len(response.Results) != 1 {
+		t.Errorf("Result = %+v, Results = %d, want code 2306 once", response.Result, len(response.Results))
+	}
+	if reasons := response.Result.ExtValues; len(reasons) != 1 || !strings.Contains(reasons[0].Value.XML, "example.at") {
+		t.Errorf("ExtValues = %+v, want the echoed domain name", reasons)
+	}
+	if len(response.Conditions) != 1 || response.Conditions[0].Code != "123" {
+		t.Errorf("Conditions = %+v, want condition 123", response.Conditions)
+	}
+	if response.ResData.InfData.Name != "example.at" {
+		t.Errorf("InfData.Name = %q, want example.at", response.ResData.InfData.Name)
+	}
+	if response.TrID.ClTRID != "client-1" || response.TrID.SvTRID != "server-1" {
+		t.Errorf("TrID = %+v", response.TrID)
+	}
+}
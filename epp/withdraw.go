@@ -1,6 +1,7 @@
 package epp
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 )
@@ -41,23 +42,36 @@ type WithdrawZoneDelete struct {
 type WithdrawResponse = Response
 
 func (c *Client) WithdrawDomainProper(domainName string) (*WithdrawResponse, error) {
-	return c.withdrawDomain(domainName, nil)
+	return c.WithdrawDomainProperContext(context.Background(), domainName)
+}
+
+func (c *Client) WithdrawDomainProperContext(ctx context.Context, domainName string) (*WithdrawResponse, error) {
+	return c.withdrawDomain(ctx, domainName, nil)
 }
 
 func (c *Client) WithdrawDomainWithZoneDelete(domainName string, zoneDelete bool) (*Response, error) {
+	return c.WithdrawDomainWithZoneDeleteContext(context.Background(), domainName, zoneDelete)
+}
+
+func (c *Client) WithdrawDomainWithZoneDeleteContext(ctx context.Context, domainName string, zoneDelete bool) (*Response, error) {
 	value := 0
 	if zoneDelete {
 		value = 1
 	}
-	return c.withdrawDomain(domainName, &value)
+	return c.withdrawDomain(ctx, domainName, &value)
 }
 
-func (c *Client) withdrawDomain(domainName string, zoneDelete *int) (*Response, error) {
+func (c *Client) withdrawDomain(ctx context.Context, domainName string, zoneDelete *int) (*Response, error) {
 	var zd *WithdrawZoneDelete
 	if zoneDelete != nil {
 		zd = &WithdrawZoneDelete{Value: *zoneDelete}
 	}
 
+	clTRID, err := c.transactionID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	withdrawReq := WithdrawRequest{
 		XMLName: xml.Name{Local: "epp"},
 		Xmlns:   "urn:ietf:params:xml:ns:epp-1.0",
@@ -74,7 +88,7 @@ func (c *Client) withdrawDomain(domainName string, zoneDelete *int) (*Response,
 						ZoneDelete: zd,
 					},
 				},
-				ClTRID: generateTransactionID(),
+				ClTRID: clTRID,
 			},
 		},
 	}
@@ -84,18 +98,18 @@ func (c *Client) withdrawDomain(domainName string, zoneDelete *int) (*Response,
 		return nil, fmt.Errorf("failed to marshal withdraw request: %w", err)
 	}
 
-	responseXML, err := c.sendRequest(requestXML)
+	reply, err := c.sendRequest(ctx, command{name: "domain:withdraw", typ: CommandWithdraw, object: domainName, extensions: []string{NamespaceAtExtEPP, NamespaceAtExtDomain}}, requestXML)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send withdraw request: %w", err)
 	}
 
 	var response Response
-	if err := xml.Unmarshal(responseXML, &response); err != nil {
+	if err := reply.decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal withdraw response: %w", err)
 	}
 
 	if response.Result.Code != "1000" {
-		return nil, fmt.Errorf("withdraw failed: %s - %s", response.Result.Code, response.Result.Msg)
+		return nil, newEPPError("withdraw", reply)
 	}
 
 	return &response, nil
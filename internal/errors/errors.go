@@ -1,35 +1,5 @@
 package errors
 
-import (
-	"fmt"
-)
-
-type EPPError struct {
-	Code    string
-	Message string
-	Details string
-}
-
-func (e *EPPError) Error() string {
-	if e.Details != "" {
-		return fmt.Sprintf("EPP error %s: %s (%s)", e.Code, e.Message, e.Details)
-	}
-	return fmt.Sprintf("EPP error %s: %s", e.Code, e.Message)
-}
-
-func IsEPPError(err error) bool {
-	_, ok := err.(*EPPError)
-	return ok
-}
-
-func NewEPPError(code, message, details string) *EPPError {
-	return &EPPError{
-		Code:    code,
-		Message: message,
-		Details: details,
-	}
-}
-
 const (
 	CodeSuccess              = "1000" // Command completed successfully
 	CodeSuccessActionPending = "1001" // Command completed successfully; action pending